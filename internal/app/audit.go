@@ -0,0 +1,286 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// 監査の項目名。git config のキー以外のもの。
+const (
+	auditProtocol  = "remote.protocol"
+	auditHost      = "remote.host"
+	auditDuplicate = "duplicate"
+)
+
+// AuditFinding はリポジトリの設定とプロファイルの食い違い。
+type AuditFinding struct {
+	Profile string `json:"profile"`
+	Repo    string `json:"repo"`
+	Path    string `json:"path"`
+	// Check は git config のキー、remote.protocol、remote.host、duplicate のいずれか
+	Check    string `json:"check"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+	// Fixable は --fix で直せる (switch と同じ git config の) 項目かを表す
+	Fixable bool `json:"fixable"`
+	Fixed   bool `json:"fixed,omitempty"`
+}
+
+// AuditReport はすべてのプロファイルの root 配下のリポジトリの監査結果。
+type AuditReport struct {
+	// Repos は調べたリポジトリの数
+	Repos    int            `json:"repos"`
+	Findings []AuditFinding `json:"findings"`
+	Warnings []string       `json:"warnings,omitempty"`
+}
+
+// Failed は直っていない食い違いがあるかを返す。
+func (r AuditReport) Failed() bool {
+	for _, f := range r.Findings {
+		if !f.Fixed {
+			return true
+		}
+	}
+	return false
+}
+
+type Auditor struct {
+	loader    ConfigLoader
+	scanner   DirScanner
+	git       GitInspector
+	writer    GitConfigWriter
+	protocols GitProtocolResolver
+}
+
+func NewAuditor(loader ConfigLoader, scanner DirScanner, git GitInspector, writer GitConfigWriter, protocols GitProtocolResolver) *Auditor {
+	return &Auditor{
+		loader:    loader,
+		scanner:   scanner,
+		git:       git,
+		writer:    writer,
+		protocols: protocols,
+	}
+}
+
+// auditedRepo は監査するリポジトリと、それを root 配下に持つプロファイル。
+type auditedRepo struct {
+	profile domain.Profile
+	// protocol は origin に期待するプロトコル。空ならどちらでもよい
+	protocol string
+	dir      domain.RepoDir
+	findings []AuditFinding
+}
+
+// Audit はすべてのプロファイルの root 配下のリポジトリについて、
+// git config (user.name、user.email、core.sshCommand)、origin のプロトコルとホストをプロファイルと比べ、
+// 複数のプロファイルの root に clone された同じリポジトリを探す。
+// fix なら git config の食い違いを switch と同じ設定で直す。
+func (a *Auditor) Audit(fix bool) (AuditReport, error) {
+	report := AuditReport{Findings: []AuditFinding{}}
+
+	profiles, err := a.loader.Load()
+	if err != nil {
+		return report, err
+	}
+
+	scanned := make([][]domain.RepoDir, len(profiles))
+	scanErrs := make([]error, len(profiles))
+	protocols := make([]string, len(profiles))
+	protocolErrs := make([]error, len(profiles))
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		if p.Root == "" {
+			continue
+		}
+		wg.Add(1)
+		go func(idx int, prof domain.Profile) {
+			defer wg.Done()
+			scanned[idx], scanErrs[idx] = scanRoot(a.scanner, prof)
+			protocols[idx], protocolErrs[idx] = a.expectedProtocol(prof)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var repos []*auditedRepo
+	for i, p := range profiles {
+		if scanErrs[i] != nil {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("profile %q: %v", p.Name, scanErrs[i]))
+		}
+		if protocolErrs[i] != nil && len(scanned[i]) > 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("profile %q: git_protocol: %v", p.Name, protocolErrs[i]))
+		}
+		for _, dir := range scanned[i] {
+			repos = append(repos, &auditedRepo{profile: p, protocol: protocols[i], dir: dir})
+		}
+	}
+	report.Repos = len(repos)
+
+	var mu sync.Mutex
+	parallel(len(repos), statusWorkers, func(i int) {
+		r := repos[i]
+		if err := a.check(r); err != nil {
+			mu.Lock()
+			report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", r.dir.Path, err))
+			mu.Unlock()
+		}
+	})
+	findDuplicates(repos)
+
+	if fix {
+		parallel(len(repos), statusWorkers, func(i int) {
+			if err := a.fix(repos[i]); err != nil {
+				mu.Lock()
+				report.Warnings = append(report.Warnings, fmt.Sprintf("%s: %v", repos[i].dir.Path, err))
+				mu.Unlock()
+			}
+		})
+	}
+
+	for _, r := range repos {
+		report.Findings = append(report.Findings, r.findings...)
+	}
+	sort.Strings(report.Warnings)
+	return report, nil
+}
+
+// check は r の git config と origin をプロファイルと比べる。bare リポジトリは origin だけを比べる。
+func (a *Auditor) check(r *auditedRepo) error {
+	p := r.profile
+	if !r.dir.Bare {
+		for _, s := range p.GitSettings() {
+			actual, err := a.git.LocalConfig(r.dir.Path, s.Key)
+			if err != nil {
+				return err
+			}
+			if actual != s.Value {
+				r.add(s.Key, s.Value, actual, true)
+			}
+		}
+	}
+
+	origin := r.dir.Origin
+	if origin.Owner == "" {
+		return nil
+	}
+	if r.protocol != "" && origin.Protocol != r.protocol {
+		r.add(auditProtocol, r.protocol, origin.Protocol, false)
+	}
+	if !strings.EqualFold(origin.Host, p.HostName()) {
+		r.add(auditHost, p.HostName(), origin.Host, false)
+	}
+	return nil
+}
+
+// expectedProtocol は p の clone の origin に期待するプロトコルを返す。
+// gh repo clone と同じく hosts.yml の git_protocol を優先し、
+// 未設定なら ssh_identity があれば ssh、なければどちらでもよい (空文字列)。
+func (a *Auditor) expectedProtocol(p domain.Profile) (string, error) {
+	protocol, err := a.protocols.ResolveGitProtocol(p)
+	if protocol == "" && p.SSHIdentity != "" {
+		protocol = "ssh"
+	}
+	return protocol, err
+}
+
+// fix は r の直せる食い違いを switch と同じ設定で直す。
+func (a *Auditor) fix(r *auditedRepo) error {
+	fixable := false
+	for _, f := range r.findings {
+		fixable = fixable || f.Fixable
+	}
+	if !fixable {
+		return nil
+	}
+	if err := ApplyGitSettings(a.writer, r.dir.Path, r.profile); err != nil {
+		return err
+	}
+	for i := range r.findings {
+		r.findings[i].Fixed = r.findings[i].Fixable
+	}
+	return nil
+}
+
+// findDuplicates は異なるプロファイルの root 配下に clone された同じリポジトリ (host と owner/repo が同じもの) を探す。
+func findDuplicates(repos []*auditedRepo) {
+	byRepo := make(map[string][]*auditedRepo)
+	for _, r := range repos {
+		ref := r.dir.Actual()
+		key := strings.ToLower(ref.Host + "/" + ref.FullName())
+		byRepo[key] = append(byRepo[key], r)
+	}
+
+	for _, r := range repos {
+		ref := r.dir.Actual()
+		var others []string
+		for _, o := range byRepo[strings.ToLower(ref.Host+"/"+ref.FullName())] {
+			if o.profile.Name != r.profile.Name {
+				others = append(others, fmt.Sprintf("%s (%s)", o.dir.Path, o.profile.Name))
+			}
+		}
+		if len(others) > 0 {
+			r.add(auditDuplicate, "", "also cloned at "+strings.Join(others, ", "), false)
+		}
+	}
+}
+
+func (r *auditedRepo) add(check, expected, actual string, fixable bool) {
+	r.findings = append(r.findings, AuditFinding{
+		Profile:  r.profile.Name,
+		Repo:     r.dir.FullName(),
+		Path:     r.dir.Path,
+		Check:    check,
+		Expected: expected,
+		Actual:   actual,
+		Fixable:  fixable,
+	})
+}
+
+// FormatAuditReport は監査結果を表にして w に出力する。
+func FormatAuditReport(r AuditReport, w io.Writer) {
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	fixedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	unset := func(v string, style lipgloss.Style) tableCell {
+		if v == "" {
+			return styled(dimStyle, "(unset)")
+		}
+		return styled(style, v)
+	}
+
+	if len(r.Findings) == 0 {
+		_, _ = fmt.Fprintf(w, "No problems found in %d repositories\n", r.Repos)
+	} else {
+		rows := [][]tableCell{{{text: "PROFILE"}, {text: "REPO"}, {text: "CHECK"}, {text: "EXPECTED"}, {text: "ACTUAL"}}}
+		unfixed := 0
+		for _, f := range r.Findings {
+			expected := unset(f.Expected, lipgloss.NewStyle())
+			if f.Check == auditDuplicate {
+				expected = tableCell{text: "-"}
+			}
+			actual := unset(f.Actual, errorStyle)
+			if f.Fixed {
+				actual = styled(fixedStyle, "fixed")
+			} else if f.Fixable {
+				unfixed++
+			}
+			rows = append(rows, []tableCell{{text: f.Profile}, {text: f.Repo}, {text: f.Check}, expected, actual})
+		}
+		writeTable(w, rows)
+		_, _ = fmt.Fprintf(w, "\n%d problems in %d repositories\n", len(r.Findings), r.Repos)
+		if unfixed > 0 {
+			_, _ = fmt.Fprintln(w, "Run `gh mrepo audit --fix` to apply each profile's git config, as `switch` does.")
+		}
+	}
+
+	for _, warn := range r.Warnings {
+		_, _ = fmt.Fprintln(w, errorStyle.Render("warning: "+warn))
+	}
+}
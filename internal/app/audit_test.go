@@ -0,0 +1,309 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/app"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// --- mock for GitConfigWriter ---
+
+type mockGitWriter struct {
+	mu     sync.Mutex
+	config map[string]map[string]string // リポジトリのパス -> key -> value。書き込みを反映する
+	err    error
+}
+
+func (m *mockGitWriter) SetLocalConfig(dir, key, value string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	if m.config[dir] == nil {
+		m.config[dir] = map[string]string{}
+	}
+	m.config[dir][key] = value
+	return nil
+}
+
+func (m *mockGitWriter) UnsetLocalConfig(dir, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	delete(m.config[dir], key)
+	return nil
+}
+
+// --- mock for GitProtocolResolver ---
+
+type mockProtocols struct {
+	protocols map[string]string // プロファイル名 -> git_protocol
+	err       error
+}
+
+func (m *mockProtocols) ResolveGitProtocol(profile domain.Profile) (string, error) {
+	return m.protocols[profile.Name], m.err
+}
+
+func newAuditFixture() (*mockLoader, *mockScanner, *mockGit) {
+	work := domain.Profile{
+		Name:           "work",
+		GHConfigDir:    "/path/work",
+		Root:           "/home/work",
+		GitConfigEmail: "me@corp.example.com",
+		SSHIdentity:    "/keys/id_work",
+	}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/home/personal"}
+
+	scanner := &mockScanner{
+		repos: map[string][]string{
+			"/home/work":     {"corp/api", "corp/web", "octocat/dotfiles"},
+			"/home/personal": {"octocat/dotfiles"},
+		},
+		origins: map[string]string{
+			"/home/work/corp/api":             "git@github.com:corp/api.git",
+			"/home/work/corp/web":             "https://ghe.example.com/corp/web.git",
+			"/home/work/octocat/dotfiles":     "git@github.com:octocat/dotfiles.git",
+			"/home/personal/octocat/dotfiles": "https://github.com/octocat/dotfiles.git",
+		},
+		errs: map[string]error{},
+	}
+	git := &mockGit{config: map[string]map[string]string{
+		"/home/work/corp/api": {"user.email": "me@corp.example.com", "core.sshCommand": "ssh -i /keys/id_work -o IdentitiesOnly=yes"},
+		"/home/work/corp/web": {"user.email": "me@personal.example.com"},
+		"/home/work/octocat/dotfiles": {
+			"user.email": "me@corp.example.com", "core.sshCommand": "ssh -i /keys/id_work -o IdentitiesOnly=yes",
+		},
+		"/home/personal/octocat/dotfiles": {"core.sshCommand": "ssh -i /keys/id_work -o IdentitiesOnly=yes"},
+	}}
+	return &mockLoader{profiles: []domain.Profile{work, personal}}, scanner, git
+}
+
+func TestAuditor_Audit(t *testing.T) {
+	loader, scanner, git := newAuditFixture()
+
+	auditor := app.NewAuditor(loader, scanner, git, &mockGitWriter{config: git.config}, &mockProtocols{})
+	report, err := auditor.Audit(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Repos != 4 {
+		t.Errorf("Repos = %d, want 4", report.Repos)
+	}
+	if !report.Failed() {
+		t.Error("report should fail")
+	}
+
+	type finding struct {
+		profile, path, check, expected, actual string
+		fixable                                bool
+	}
+	want := []finding{
+		{"work", "/home/work/corp/web", "user.email", "me@corp.example.com", "me@personal.example.com", true},
+		{"work", "/home/work/corp/web", "core.sshCommand", "ssh -i /keys/id_work -o IdentitiesOnly=yes", "", true},
+		{"work", "/home/work/corp/web", "remote.protocol", "ssh", "https", false},
+		{"work", "/home/work/corp/web", "remote.host", "github.com", "ghe.example.com", false},
+		{"work", "/home/work/octocat/dotfiles", "duplicate", "", "also cloned at /home/personal/octocat/dotfiles (personal)", false},
+		{"personal", "/home/personal/octocat/dotfiles", "core.sshCommand", "", "ssh -i /keys/id_work -o IdentitiesOnly=yes", true},
+		{"personal", "/home/personal/octocat/dotfiles", "duplicate", "", "also cloned at /home/work/octocat/dotfiles (work)", false},
+	}
+	if len(report.Findings) != len(want) {
+		t.Fatalf("findings = %+v, want %d findings", report.Findings, len(want))
+	}
+	for i, w := range want {
+		f := report.Findings[i]
+		got := finding{f.Profile, f.Path, f.Check, f.Expected, f.Actual, f.Fixable}
+		if got != w || f.Fixed {
+			t.Errorf("findings[%d] = %+v, want %+v", i, f, w)
+		}
+	}
+}
+
+func TestAuditor_Fix(t *testing.T) {
+	loader, scanner, git := newAuditFixture()
+	writer := &mockGitWriter{config: git.config}
+
+	report, err := app.NewAuditor(loader, scanner, git, writer, &mockProtocols{}).Audit(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Fixed != f.Fixable {
+			t.Errorf("%s %s: Fixed = %v, want %v", f.Path, f.Check, f.Fixed, f.Fixable)
+		}
+	}
+	// switch と同じ設定が書き込まれる
+	if got := git.config["/home/work/corp/web"]["user.email"]; got != "me@corp.example.com" {
+		t.Errorf("user.email = %q", got)
+	}
+	if _, ok := git.config["/home/personal/octocat/dotfiles"]["core.sshCommand"]; ok {
+		t.Error("core.sshCommand should be unset for personal")
+	}
+
+	// 直したあとは git config の食い違いがなくなる
+	report, err = app.NewAuditor(loader, scanner, git, writer, &mockProtocols{}).Audit(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Fixable {
+			t.Errorf("finding after fix: %+v", f)
+		}
+	}
+}
+
+func TestAuditor_FixError(t *testing.T) {
+	loader, scanner, git := newAuditFixture()
+	writer := &mockGitWriter{config: git.config, err: errors.New("could not lock config file")}
+
+	report, err := app.NewAuditor(loader, scanner, git, writer, &mockProtocols{}).Audit(true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, f := range report.Findings {
+		if f.Fixed {
+			t.Errorf("%s %s should not be fixed", f.Path, f.Check)
+		}
+	}
+	if len(report.Warnings) != 2 || !strings.Contains(report.Warnings[0], "could not lock config file") {
+		t.Errorf("Warnings = %v, want one per repo that could not be fixed", report.Warnings)
+	}
+}
+
+func TestAuditor_NoFindings(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work"}
+	scanner := &mockScanner{
+		repos:   map[string][]string{"/home/work": {"octocat/hello"}},
+		origins: map[string]string{"/home/work/octocat/hello": "https://github.com/octocat/hello.git"},
+		errs:    map[string]error{},
+	}
+	auditor := app.NewAuditor(&mockLoader{profiles: []domain.Profile{work}}, scanner, &mockGit{}, &mockGitWriter{}, &mockProtocols{})
+
+	report, err := auditor.Audit(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed() || len(report.Findings) != 0 {
+		t.Errorf("findings = %+v, want none", report.Findings)
+	}
+
+	var buf bytes.Buffer
+	app.FormatAuditReport(report, &buf)
+	if got := buf.String(); got != "No problems found in 1 repositories\n" {
+		t.Errorf("output = %q", got)
+	}
+}
+
+func TestAuditor_GitProtocol(t *testing.T) {
+	tests := []struct {
+		name        string
+		identity    string
+		gitProtocol string
+		origin      string
+		want        string // 期待するプロトコル。空なら remote.protocol の指摘なし
+	}{
+		{name: "git_protocol: ssh で ssh_identity なし", gitProtocol: "ssh", origin: "git@github.com:octocat/hello.git"},
+		{name: "git_protocol: ssh で HTTPS の clone", gitProtocol: "ssh", origin: "https://github.com/octocat/hello.git", want: "ssh"},
+		{name: "git_protocol: https を ssh_identity より優先", identity: "/keys/id", gitProtocol: "https", origin: "git@github.com:octocat/hello.git", want: "https"},
+		{name: "git_protocol なしで ssh_identity あり", identity: "/keys/id", origin: "https://github.com/octocat/hello.git", want: "ssh"},
+		{name: "どちらもなければ HTTPS", origin: "https://github.com/octocat/hello.git"},
+		{name: "どちらもなければ SSH", origin: "git@github.com:octocat/hello.git"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work", SSHIdentity: tt.identity}
+			scanner := &mockScanner{
+				repos:   map[string][]string{"/home/work": {"octocat/hello"}},
+				origins: map[string]string{"/home/work/octocat/hello": tt.origin},
+				errs:    map[string]error{},
+			}
+			git := &mockGit{config: map[string]map[string]string{
+				"/home/work/octocat/hello": {"core.sshCommand": work.SSHCommand()},
+			}}
+			protocols := &mockProtocols{protocols: map[string]string{"work": tt.gitProtocol}}
+			auditor := app.NewAuditor(&mockLoader{profiles: []domain.Profile{work}}, scanner, git, &mockGitWriter{}, protocols)
+
+			report, err := auditor.Audit(false)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var got string
+			for _, f := range report.Findings {
+				if f.Check == "remote.protocol" {
+					got = f.Expected
+				} else {
+					t.Errorf("unexpected finding: %+v", f)
+				}
+			}
+			if got != tt.want {
+				t.Errorf("expected protocol = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuditor_GitProtocolError(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work"}
+	scanner := &mockScanner{
+		repos:   map[string][]string{"/home/work": {"octocat/hello"}},
+		origins: map[string]string{"/home/work/octocat/hello": "git@github.com:octocat/hello.git"},
+		errs:    map[string]error{},
+	}
+	protocols := &mockProtocols{err: errors.New("failed to read hosts.yml")}
+	auditor := app.NewAuditor(&mockLoader{profiles: []domain.Profile{work}}, scanner, &mockGit{}, &mockGitWriter{}, protocols)
+
+	report, err := auditor.Audit(false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(report.Findings) != 0 {
+		t.Errorf("findings = %+v, want none", report.Findings)
+	}
+	if len(report.Warnings) != 1 || !strings.Contains(report.Warnings[0], "failed to read hosts.yml") {
+		t.Errorf("Warnings = %v", report.Warnings)
+	}
+}
+
+func TestFormatAuditReport(t *testing.T) {
+	report := app.AuditReport{
+		Repos: 2,
+		Findings: []app.AuditFinding{
+			{Profile: "work", Repo: "corp/web", Check: "user.email", Expected: "me@corp.example.com", Actual: "", Fixable: true},
+			{Profile: "work", Repo: "corp/api", Check: "user.email", Expected: "me@corp.example.com", Actual: "x@y", Fixable: true, Fixed: true},
+			{Profile: "work", Repo: "corp/api", Check: "duplicate", Actual: "also cloned at /p/corp/api (personal)"},
+			{Profile: "personal", Repo: "octocat/dotfiles", Check: "core.sshCommand", Actual: "ssh -i /keys/id_work", Fixable: true},
+		},
+		Warnings: []string{`profile "personal": permission denied`},
+	}
+
+	forceColor(t)
+	var buf bytes.Buffer
+	app.FormatAuditReport(report, &buf)
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("output should be colored, got %q", buf.String())
+	}
+	// 色のエスケープを除くと列がそろっている
+	out := stripANSI(buf.String())
+	for _, want := range []string{
+		"PROFILE   REPO              CHECK            EXPECTED             ACTUAL",
+		"work      corp/web          user.email       me@corp.example.com  (unset)",
+		"work      corp/api          user.email       me@corp.example.com  fixed",
+		"work      corp/api          duplicate        -                    also cloned at /p/corp/api (personal)",
+		"personal  octocat/dotfiles  core.sshCommand  (unset)              ssh -i /keys/id_work",
+		"4 problems in 2 repositories",
+		"gh mrepo audit --fix",
+		`warning: profile "personal": permission denied`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package app
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// Source はプロファイルを決定したルールを表す。
+type Source string
+
+const (
+	SourceFlag     Source = "flag"     // --user
+	SourceEnv      Source = "env"      // GH_MREPO_PROFILE
+	SourceMarker   Source = "marker"   // .gh-mrepo.toml
+	SourceRoot     Source = "root"     // カレントディレクトリが root 配下
+	SourceSingle   Source = "single"   // プロファイルが1つだけ
+	SourceSelector Source = "selector" // 対話的に選択
+)
+
+// Query はプロファイル選択の入力。
+type Query struct {
+	Flag   string        // --user の値
+	Env    string        // GH_MREPO_PROFILE の値
+	Dir    string        // カレントディレクトリ
+	Marker domain.Marker // Dir から見つかったマーカーファイル (なければゼロ値)
+	// MarkerErr はマーカーファイルを読み込めなかったときのエラー。
+	// プロファイルをマーカーで決める段階になって初めて返す
+	MarkerErr error
+}
+
+// Choice は選択されたプロファイルと、その根拠を表す。
+type Choice struct {
+	Profile domain.Profile
+	Source  Source
+	Detail  string // 根拠の補足 (一致した root、マーカーファイルのパス)
+}
+
+func (c Choice) String() string {
+	return fmt.Sprintf("profile %q (%s)", c.Profile.Name, c.Reason())
+}
+
+// Reason はプロファイルが選ばれた根拠を返す。
+func (c Choice) Reason() string {
+	switch c.Source {
+	case SourceFlag:
+		return "--user"
+	case SourceEnv:
+		return "GH_MREPO_PROFILE"
+	case SourceMarker:
+		return "marker " + c.Detail
+	case SourceRoot:
+		return "directory under root " + c.Detail
+	case SourceSingle:
+		return "only profile"
+	default:
+		return "selected"
+	}
+}
+
+// ChooseProfile は --user、GH_MREPO_PROFILE、マーカーファイル、root、単一プロファイル、
+// セレクタの順でプロファイルを決定する。
+func ChooseProfile(profiles []domain.Profile, q Query, selector ProfileSelector) (Choice, error) {
+	if q.Flag != "" {
+		p, err := domain.FindProfile(profiles, q.Flag)
+		return Choice{Profile: p, Source: SourceFlag}, err
+	}
+	if q.Env != "" {
+		p, err := domain.FindProfile(profiles, q.Env)
+		return Choice{Profile: p, Source: SourceEnv}, err
+	}
+	if q.MarkerErr != nil {
+		return Choice{}, q.MarkerErr
+	}
+	if q.Marker.Profile != "" {
+		p, err := domain.FindProfile(profiles, q.Marker.Profile)
+		if err != nil {
+			return Choice{}, fmt.Errorf("marker %s: %w", q.Marker.Path, err)
+		}
+		return Choice{Profile: p, Source: SourceMarker, Detail: q.Marker.Path}, nil
+	}
+	if q.Dir != "" {
+		if p, err := domain.FindByDirectory(profiles, q.Dir); err == nil {
+			return Choice{Profile: p, Source: SourceRoot, Detail: p.Root}, nil
+		}
+	}
+	if len(profiles) == 1 {
+		return Choice{Profile: profiles[0], Source: SourceSingle}, nil
+	}
+	p, err := selector.Select(profiles)
+	return Choice{Profile: p, Source: SourceSelector}, err
+}
+
+// ReportChoice はディレクトリ由来のルール (マーカー、root) で選ばれた場合に、その根拠を w に出力する。
+func ReportChoice(w io.Writer, c Choice) {
+	if c.Source != SourceMarker && c.Source != SourceRoot {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "gh-mrepo: using %s\n", c)
+}
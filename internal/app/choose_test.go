@@ -0,0 +1,169 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/app"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestChooseProfile(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/user/work"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/home/user/personal"}
+	profiles := []domain.Profile{work, personal}
+
+	tests := []struct {
+		name         string
+		query        app.Query
+		wantProfile  string
+		wantSource   app.Source
+		wantSelector bool
+	}{
+		{
+			name:        "flag",
+			query:       app.Query{Flag: "work", Env: "personal", Dir: "/home/user/personal/repo"},
+			wantProfile: "work",
+			wantSource:  app.SourceFlag,
+		},
+		{
+			name:        "env",
+			query:       app.Query{Env: "personal", Dir: "/home/user/work/repo"},
+			wantProfile: "personal",
+			wantSource:  app.SourceEnv,
+		},
+		{
+			name:        "marker",
+			query:       app.Query{Dir: "/home/user/work/acme/api", Marker: domain.Marker{Path: "/home/user/work/acme/api/.gh-mrepo.toml", Profile: "personal"}},
+			wantProfile: "personal",
+			wantSource:  app.SourceMarker,
+		},
+		{
+			name:        "root",
+			query:       app.Query{Dir: "/home/user/work/acme/api"},
+			wantProfile: "work",
+			wantSource:  app.SourceRoot,
+		},
+		{
+			name:         "directory unmatched falls back to selector",
+			query:        app.Query{Dir: "/tmp"},
+			wantProfile:  "personal",
+			wantSource:   app.SourceSelector,
+			wantSelector: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector := &mockSelector{selected: personal}
+			c, err := app.ChooseProfile(profiles, tt.query, selector)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.Profile.Name != tt.wantProfile {
+				t.Errorf("Profile.Name = %q, want %q", c.Profile.Name, tt.wantProfile)
+			}
+			if c.Source != tt.wantSource {
+				t.Errorf("Source = %q, want %q", c.Source, tt.wantSource)
+			}
+			if selector.called != tt.wantSelector {
+				t.Errorf("selector.called = %v, want %v", selector.called, tt.wantSelector)
+			}
+		})
+	}
+}
+
+func TestChooseProfile_SingleProfile(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+
+	c, err := app.ChooseProfile([]domain.Profile{work}, app.Query{Dir: "/tmp"}, &mockSelector{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.Source != app.SourceSingle {
+		t.Errorf("Source = %q, want %q", c.Source, app.SourceSingle)
+	}
+}
+
+func TestChooseProfile_UnknownEnvProfile(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+
+	_, err := app.ChooseProfile([]domain.Profile{work}, app.Query{Env: "unknown"}, &mockSelector{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestChooseProfile_MarkerUnknownProfile(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+	marker := domain.Marker{Path: "/repo/.gh-mrepo.toml", Profile: "unknown"}
+
+	_, err := app.ChooseProfile([]domain.Profile{work}, app.Query{Marker: marker}, &mockSelector{})
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if !strings.Contains(err.Error(), marker.Path) {
+		t.Errorf("error should mention the marker path, got %v", err)
+	}
+}
+
+func TestChooseProfile_MarkerError(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal"}
+	profiles := []domain.Profile{work, personal}
+	markerErr := errors.New(`failed to load marker "/repo/.gh-mrepo.toml"`)
+
+	// --user や GH_MREPO_PROFILE で決まるならマーカーは読まない
+	got, err := app.ChooseProfile(profiles, app.Query{Flag: "work", MarkerErr: markerErr}, &mockSelector{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Profile.Name != "work" {
+		t.Errorf("Profile = %q, want %q", got.Profile.Name, "work")
+	}
+
+	_, err = app.ChooseProfile(profiles, app.Query{MarkerErr: markerErr}, &mockSelector{selected: personal})
+	if !errors.Is(err, markerErr) {
+		t.Errorf("err = %v, want %v", err, markerErr)
+	}
+}
+
+func TestReportChoice(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/user/work"}
+
+	var buf bytes.Buffer
+	app.ReportChoice(&buf, app.Choice{Profile: work, Source: app.SourceRoot, Detail: work.Root})
+	if !strings.Contains(buf.String(), `profile "work" (directory under root /home/user/work)`) {
+		t.Errorf("output = %q", buf.String())
+	}
+
+	buf.Reset()
+	app.ReportChoice(&buf, app.Choice{Profile: work, Source: app.SourceFlag})
+	if buf.Len() != 0 {
+		t.Errorf("output should be empty for explicit choice, got %q", buf.String())
+	}
+}
+
+func TestChooseProfile_AliasAndPrefix(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Aliases: []string{"corp"}}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal"}
+	profiles := []domain.Profile{work, personal}
+
+	for _, flag := range []string{"corp", "pers"} {
+		c, err := app.ChooseProfile(profiles, app.Query{Flag: flag}, &mockSelector{})
+		if err != nil {
+			t.Fatalf("--user %s: unexpected error: %v", flag, err)
+		}
+		if c.Source != app.SourceFlag {
+			t.Errorf("--user %s: Source = %q, want %q", flag, c.Source, app.SourceFlag)
+		}
+	}
+
+	_, err := app.ChooseProfile(profiles, app.Query{Flag: "wrok"}, &mockSelector{})
+	var notFound *domain.ProfileNotFoundError
+	if !errors.As(err, &notFound) || len(notFound.Suggestions) == 0 || notFound.Suggestions[0] != "work" {
+		t.Errorf("err = %v, want not found with suggestion work", err)
+	}
+}
@@ -0,0 +1,273 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// CheckStatus は診断結果の重要度。
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+// minAuthSwitchVersion は gh auth switch が使える最小の gh バージョン。
+var minAuthSwitchVersion = [3]int{2, 40, 0}
+
+// Check は1つの診断項目の結果。Profile が空の場合は全体に対する診断。
+type Check struct {
+	Profile string      `json:"profile,omitempty"`
+	Name    string      `json:"check"`
+	Status  CheckStatus `json:"status"`
+	Message string      `json:"message"`
+}
+
+// DoctorReport は全プロファイルの診断結果。
+type DoctorReport struct {
+	Checks []Check `json:"checks"`
+}
+
+// Failed は fail の診断が1つでもあるかを返す。
+func (r DoctorReport) Failed() bool {
+	for _, c := range r.Checks {
+		if c.Status == CheckFail {
+			return true
+		}
+	}
+	return false
+}
+
+type Doctor struct {
+	loader   ConfigLoader
+	resolver UserResolver
+	executor GHExecutor
+	version  GHVersioner
+}
+
+func NewDoctor(loader ConfigLoader, resolver UserResolver, executor GHExecutor, version GHVersioner) *Doctor {
+	return &Doctor{
+		loader:   loader,
+		resolver: resolver,
+		executor: executor,
+		version:  version,
+	}
+}
+
+// Diagnose は gh のバージョンと、各プロファイルの設定・認証状態を検査する。
+func (d *Doctor) Diagnose() (DoctorReport, error) {
+	profiles, err := d.loader.Load()
+	if err != nil {
+		return DoctorReport{}, err
+	}
+
+	report := DoctorReport{Checks: []Check{d.checkGHVersion()}}
+
+	checksByIdx := make([][]Check, len(profiles))
+	users := make([]string, len(profiles))
+
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		wg.Add(1)
+		go func(idx int, prof domain.Profile) {
+			defer wg.Done()
+			checksByIdx[idx], users[idx] = d.checkProfile(prof)
+		}(i, p)
+	}
+	wg.Wait()
+
+	for i, p := range profiles {
+		checks := checksByIdx[i]
+		checks = append(checks, checkSharedRoot(profiles, i), checkSharedAccount(profiles, users, i))
+		for _, c := range checks {
+			if c.Name == "" {
+				continue
+			}
+			c.Profile = p.Name
+			report.Checks = append(report.Checks, c)
+		}
+	}
+	return report, nil
+}
+
+func (d *Doctor) checkGHVersion() Check {
+	c := Check{Name: "gh version"}
+	v, err := d.version.GHVersion()
+	if err != nil {
+		c.Status, c.Message = CheckFail, err.Error()
+		return c
+	}
+	if !versionAtLeast(v, minAuthSwitchVersion) {
+		c.Status = CheckFail
+		c.Message = fmt.Sprintf("gh %s does not support `gh auth switch` (requires %d.%d.%d or later)",
+			v, minAuthSwitchVersion[0], minAuthSwitchVersion[1], minAuthSwitchVersion[2])
+		return c
+	}
+	c.Status, c.Message = CheckPass, "gh "+v
+	return c
+}
+
+// checkProfile は1プロファイル分の診断を行い、解決できた GitHub ユーザー名を返す。
+func (d *Doctor) checkProfile(p domain.Profile) ([]Check, string) {
+	var checks []Check
+
+	dirCheck := Check{Name: "gh_config_dir"}
+	if info, err := os.Stat(p.GHConfigDir); err != nil || !info.IsDir() {
+		dirCheck.Status, dirCheck.Message = CheckFail, fmt.Sprintf("%s does not exist", p.GHConfigDir)
+		checks = append(checks, dirCheck, skipped("hosts.yml"), skipped("auth"))
+		return append(checks, checkSSHIdentity(p), checkRoot(p)), ""
+	}
+	dirCheck.Status, dirCheck.Message = CheckPass, p.GHConfigDir
+	checks = append(checks, dirCheck)
+
+	hostsCheck := Check{Name: "hosts.yml"}
+	user, err := d.resolver.ResolveGitHubUser(p)
+	if err != nil {
+		hostsCheck.Status, hostsCheck.Message = CheckFail, err.Error()
+		checks = append(checks, hostsCheck, skipped("auth"))
+		return append(checks, checkSSHIdentity(p), checkRoot(p)), ""
+	}
+	hostsCheck.Status, hostsCheck.Message = CheckPass, fmt.Sprintf("%s@%s", user, p.HostName())
+	checks = append(checks, hostsCheck)
+
+	authCheck := Check{Name: "auth"}
+	if _, err := d.executor.ExecCapture(p, []string{"auth", "status", "--hostname", p.HostName()}); err != nil {
+		// gh の出力は複数行になるので1行目だけを表示する
+		msg, _, _ := strings.Cut(err.Error(), "\n")
+		authCheck.Status = CheckFail
+		authCheck.Message = fmt.Sprintf("%s (run: %s)", msg, p.AuthLoginCommand())
+	} else {
+		authCheck.Status, authCheck.Message = CheckPass, "token is valid"
+	}
+	checks = append(checks, authCheck)
+
+	return append(checks, checkSSHIdentity(p), checkRoot(p)), user
+}
+
+func checkSSHIdentity(p domain.Profile) Check {
+	c := Check{Name: "ssh_identity"}
+	if p.SSHIdentity == "" {
+		c.Status, c.Message = CheckPass, "not set (HTTPS)"
+		return c
+	}
+	info, err := os.Stat(p.SSHIdentity)
+	if err != nil {
+		c.Status, c.Message = CheckFail, fmt.Sprintf("%s does not exist", p.SSHIdentity)
+		return c
+	}
+	// ssh はグループ・その他から読める秘密鍵を拒否する
+	if perm := info.Mode().Perm(); perm&0o077 != 0 {
+		c.Status = CheckFail
+		c.Message = fmt.Sprintf("%s has unsafe permissions %04o (run: chmod 600 %s)", p.SSHIdentity, perm, p.SSHIdentity)
+		return c
+	}
+	c.Status, c.Message = CheckPass, p.SSHIdentity
+	return c
+}
+
+func checkRoot(p domain.Profile) Check {
+	c := Check{Name: "root"}
+	if p.Root == "" {
+		c.Status, c.Message = CheckPass, "not set"
+		return c
+	}
+	if info, err := os.Stat(p.Root); err != nil || !info.IsDir() {
+		c.Status, c.Message = CheckWarn, fmt.Sprintf("%s does not exist", p.Root)
+		return c
+	}
+	c.Status, c.Message = CheckPass, p.Root
+	return c
+}
+
+// checkSharedRoot は profiles[idx] と同じ root を持つプロファイルがないかを検査する。
+// layout の {host} でリポジトリの置き場所が分かれるプロファイル同士は共有とみなさない。
+func checkSharedRoot(profiles []domain.Profile, idx int) Check {
+	p := profiles[idx]
+	if p.Root == "" {
+		return Check{}
+	}
+	var others []string
+	for i, o := range profiles {
+		if i != idx && o.Root != "" && filepath.Clean(o.RepoRoot()) == filepath.Clean(p.RepoRoot()) {
+			others = append(others, o.Name)
+		}
+	}
+	if len(others) > 0 {
+		return Check{Name: "unique root", Status: CheckFail,
+			Message: fmt.Sprintf("root is shared with %s", strings.Join(others, ", "))}
+	}
+	return Check{Name: "unique root", Status: CheckPass, Message: p.Root}
+}
+
+// checkSharedAccount は profiles[idx] と同じアカウント (host と user) を使うプロファイルがないかを検査する。
+func checkSharedAccount(profiles []domain.Profile, users []string, idx int) Check {
+	if users[idx] == "" {
+		return Check{}
+	}
+	p := profiles[idx]
+	var others []string
+	for i, o := range profiles {
+		if i != idx && users[i] == users[idx] && o.HostName() == p.HostName() {
+			others = append(others, o.Name)
+		}
+	}
+	account := users[idx] + "@" + p.HostName()
+	if len(others) > 0 {
+		return Check{Name: "unique account", Status: CheckWarn,
+			Message: fmt.Sprintf("%s is shared with %s", account, strings.Join(others, ", "))}
+	}
+	return Check{Name: "unique account", Status: CheckPass, Message: account}
+}
+
+func skipped(name string) Check {
+	return Check{Name: name, Status: CheckWarn, Message: "skipped"}
+}
+
+var versionRe = regexp.MustCompile(`(\d+)\.(\d+)\.(\d+)`)
+
+// versionAtLeast は "2.45.0" 形式のバージョンが minVersion 以上かを返す。
+func versionAtLeast(version string, minVersion [3]int) bool {
+	m := versionRe.FindStringSubmatch(version)
+	if m == nil {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		n, _ := strconv.Atoi(m[i+1])
+		if n != minVersion[i] {
+			return n > minVersion[i]
+		}
+	}
+	return true
+}
+
+// FormatDoctorReport は診断結果を表形式で w に出力する。
+func FormatDoctorReport(r DoctorReport, w io.Writer) {
+	styles := map[CheckStatus]lipgloss.Style{
+		CheckPass: lipgloss.NewStyle().Foreground(lipgloss.Color("2")),
+		CheckWarn: lipgloss.NewStyle().Foreground(lipgloss.Color("3")),
+		CheckFail: lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	}
+
+	rows := [][]tableCell{{{text: "STATUS"}, {text: "PROFILE"}, {text: "CHECK"}, {text: "MESSAGE"}}}
+	for _, c := range r.Checks {
+		profile := c.Profile
+		if profile == "" {
+			profile = "-"
+		}
+		status := styled(styles[c.Status], strings.ToUpper(string(c.Status)))
+		rows = append(rows, []tableCell{status, {text: profile}, {text: c.Name}, {text: c.Message}})
+	}
+	writeTable(w, rows)
+}
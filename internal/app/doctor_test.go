@@ -0,0 +1,237 @@
+package app_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/sarrrrry/gh-mrepo/internal/app"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+type mockVersioner struct {
+	version string
+	err     error
+}
+
+func (m *mockVersioner) GHVersion() (string, error) {
+	return m.version, m.err
+}
+
+// findCheck は profile と name に一致する診断結果を返す。
+func findCheck(t *testing.T, r app.DoctorReport, profile, name string) app.Check {
+	t.Helper()
+	for _, c := range r.Checks {
+		if c.Profile == profile && c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %q for profile %q not found in %+v", name, profile, r.Checks)
+	return app.Check{}
+}
+
+func TestDoctor_AllPass(t *testing.T) {
+	base := t.TempDir()
+	ghDir := filepath.Join(base, "gh-work")
+	root := filepath.Join(base, "repos", "work")
+	key := filepath.Join(base, "id_work")
+	mkdirAll(t, ghDir, root)
+	if err := os.WriteFile(key, []byte("key"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	work := domain.Profile{Name: "work", GHConfigDir: ghDir, Root: root, SSHIdentity: key}
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	resolver := &mockResolver{users: map[string]string{ghDir: "octocat-work"}, err: map[string]error{}}
+	executor := &mockCaptureExecutor{outputs: map[string]string{}, errs: map[string]error{}}
+
+	d := app.NewDoctor(loader, resolver, executor, &mockVersioner{version: "2.45.0"})
+	report, err := d.Diagnose()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if report.Failed() {
+		t.Errorf("report should not fail: %+v", report.Checks)
+	}
+	for _, c := range report.Checks {
+		if c.Status != app.CheckPass {
+			t.Errorf("check %q (%s) = %s: %s", c.Name, c.Profile, c.Status, c.Message)
+		}
+	}
+}
+
+func TestDoctor_Failures(t *testing.T) {
+	base := t.TempDir()
+	ghWork := filepath.Join(base, "gh-work")
+	ghOSS := filepath.Join(base, "gh-oss")
+	root := filepath.Join(base, "repos")
+	key := filepath.Join(base, "id_work")
+	mkdirAll(t, ghWork, ghOSS, root)
+	if err := os.WriteFile(key, []byte("key"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	work := domain.Profile{Name: "work", GHConfigDir: ghWork, Root: root, SSHIdentity: key}
+	oss := domain.Profile{Name: "oss", GHConfigDir: ghOSS, Root: root}
+	missing := domain.Profile{Name: "missing", GHConfigDir: filepath.Join(base, "nope")}
+
+	loader := &mockLoader{profiles: []domain.Profile{work, oss, missing}}
+	resolver := &mockResolver{
+		users: map[string]string{ghWork: "octocat", ghOSS: "octocat"},
+		err:   map[string]error{},
+	}
+	executor := &mockCaptureExecutor{
+		outputs: map[string]string{},
+		errs:    map[string]error{ghOSS: errors.New("The token in hosts.yml is invalid")},
+	}
+
+	d := app.NewDoctor(loader, resolver, executor, &mockVersioner{version: "2.30.0"})
+	report, err := d.Diagnose()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !report.Failed() {
+		t.Fatal("report should fail")
+	}
+
+	tests := []struct {
+		profile string
+		check   string
+		want    app.CheckStatus
+	}{
+		{"", "gh version", app.CheckFail},
+		{"work", "ssh_identity", app.CheckFail},
+		{"work", "auth", app.CheckPass},
+		{"oss", "auth", app.CheckFail},
+		{"work", "unique root", app.CheckFail},
+		{"oss", "unique account", app.CheckWarn},
+		{"missing", "gh_config_dir", app.CheckFail},
+	}
+	for _, tt := range tests {
+		c := findCheck(t, report, tt.profile, tt.check)
+		if c.Status != tt.want {
+			t.Errorf("%s/%s = %s (%s), want %s", tt.profile, tt.check, c.Status, c.Message, tt.want)
+		}
+	}
+}
+
+func TestDoctor_SharedRootSeparatedByHost(t *testing.T) {
+	base := t.TempDir()
+	ghWork := filepath.Join(base, "gh-work")
+	ghOSS := filepath.Join(base, "gh-oss")
+	root := filepath.Join(base, "ghq")
+	mkdirAll(t, ghWork, ghOSS, root)
+	ghq, err := domain.ParseLayout("{host}/{owner}/{repo}")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	work := domain.Profile{Name: "work", GHConfigDir: ghWork, Root: root, Host: "ghe.example.com", Layout: ghq}
+	oss := domain.Profile{Name: "oss", GHConfigDir: ghOSS, Root: root, Layout: ghq}
+	loader := &mockLoader{profiles: []domain.Profile{work, oss}}
+	resolver := &mockResolver{users: map[string]string{ghWork: "octocat-work", ghOSS: "octocat"}, err: map[string]error{}}
+	executor := &mockCaptureExecutor{outputs: map[string]string{}, errs: map[string]error{}}
+
+	d := app.NewDoctor(loader, resolver, executor, &mockVersioner{version: "2.45.0"})
+	report, err := d.Diagnose()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"work", "oss"} {
+		if c := findCheck(t, report, name, "unique root"); c.Status != app.CheckPass {
+			t.Errorf("%s/unique root = %s (%s), want pass", name, c.Status, c.Message)
+		}
+	}
+}
+
+func TestDoctor_HostsError(t *testing.T) {
+	ghDir := t.TempDir()
+	work := domain.Profile{Name: "work", GHConfigDir: ghDir}
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	resolver := &mockResolver{err: map[string]error{ghDir: errors.New("failed to parse hosts.yml")}}
+	executor := &mockCaptureExecutor{}
+
+	d := app.NewDoctor(loader, resolver, executor, &mockVersioner{version: "2.45.0"})
+	report, err := d.Diagnose()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c := findCheck(t, report, "work", "hosts.yml"); c.Status != app.CheckFail {
+		t.Errorf("hosts.yml = %s, want fail", c.Status)
+	}
+}
+
+func TestFormatDoctorReport(t *testing.T) {
+	report := app.DoctorReport{Checks: []app.Check{
+		{Name: "gh version", Status: app.CheckPass, Message: "gh 2.45.0"},
+		{Profile: "work", Name: "root", Status: app.CheckWarn, Message: "/repos does not exist"},
+	}}
+
+	var buf bytes.Buffer
+	app.FormatDoctorReport(report, &buf)
+	out := buf.String()
+	for _, want := range []string{"STATUS", "PASS", "WARN", "work", "/repos does not exist"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output should contain %q, got:\n%s", want, out)
+		}
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"status":"warn"`) {
+		t.Errorf("json = %s", data)
+	}
+}
+
+func TestFormatDoctorReport_Colored(t *testing.T) {
+	forceColor(t)
+	report := app.DoctorReport{Checks: []app.Check{
+		{Name: "gh version", Status: app.CheckPass, Message: "gh 2.45.0"},
+		{Profile: "work", Name: "root", Status: app.CheckFail, Message: "/repos does not exist"},
+	}}
+
+	var buf bytes.Buffer
+	app.FormatDoctorReport(report, &buf)
+	if !strings.Contains(buf.String(), "\x1b[") {
+		t.Fatalf("output should be colored, got %q", buf.String())
+	}
+	// 色のエスケープを除くと列がそろっている
+	want := "STATUS  PROFILE  CHECK       MESSAGE\n" +
+		"PASS    -        gh version  gh 2.45.0\n" +
+		"FAIL    work     root        /repos does not exist\n"
+	if got := stripANSI(buf.String()); got != want {
+		t.Errorf("output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// forceColor はテストの間 lipgloss に色を出力させる。
+func forceColor(t *testing.T) {
+	t.Helper()
+	prev := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(0) // termenv.TrueColor
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+}
+
+var ansiRe = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+func stripANSI(s string) string {
+	return ansiRe.ReplaceAllString(s, "")
+}
+
+func mkdirAll(t *testing.T, dirs ...string) {
+	t.Helper()
+	for _, d := range dirs {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package app
+
+import "io"
+
+// CommandRunner は選択したプロファイルの環境で任意のコマンドを実行する。
+type CommandRunner struct {
+	loader   ConfigLoader
+	selector ProfileSelector
+	executor CommandExecutor
+	log      io.Writer
+}
+
+func NewCommandRunner(loader ConfigLoader, selector ProfileSelector, executor CommandExecutor) *CommandRunner {
+	return &CommandRunner{
+		loader:   loader,
+		selector: selector,
+		executor: executor,
+		log:      io.Discard,
+	}
+}
+
+// SetLogOutput はプロファイル選択の根拠の出力先を設定する。
+func (r *CommandRunner) SetLogOutput(w io.Writer) {
+	r.log = w
+}
+
+func (r *CommandRunner) Run(q Query, argv []string) error {
+	profiles, err := r.loader.Load()
+	if err != nil {
+		return err
+	}
+
+	choice, err := ChooseProfile(profiles, q, r.selector)
+	if err != nil {
+		return err
+	}
+	ReportChoice(r.log, choice)
+	selected := choice.Profile
+
+	if err := r.executor.ExecCommand(selected, argv); err != nil {
+		return &ProfileError{Profile: selected, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package app_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/app"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+type mockCommandExecutor struct {
+	profile domain.Profile
+	argv    []string
+	err     error
+	called  bool
+}
+
+func (m *mockCommandExecutor) ExecCommand(profile domain.Profile, argv []string) error {
+	m.called = true
+	m.profile = profile
+	m.argv = argv
+	return m.err
+}
+
+func TestCommandRunner_UserFlag(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal"}
+
+	loader := &mockLoader{profiles: []domain.Profile{work, personal}}
+	selector := &mockSelector{}
+	executor := &mockCommandExecutor{}
+
+	r := app.NewCommandRunner(loader, selector, executor)
+	argv := []string{"git", "push"}
+	if err := r.Run(app.Query{Flag: "personal"}, argv); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if selector.called {
+		t.Error("selector should not be called when --user is specified")
+	}
+	if executor.profile.Name != "personal" {
+		t.Errorf("executor.profile.Name = %q, want %q", executor.profile.Name, "personal")
+	}
+	if len(executor.argv) != 2 || executor.argv[0] != "git" || executor.argv[1] != "push" {
+		t.Errorf("executor.argv = %v, want %v", executor.argv, argv)
+	}
+}
+
+func TestCommandRunner_MultipleProfiles_SelectorCalled(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal"}
+
+	loader := &mockLoader{profiles: []domain.Profile{work, personal}}
+	selector := &mockSelector{selected: work}
+	executor := &mockCommandExecutor{}
+
+	r := app.NewCommandRunner(loader, selector, executor)
+	if err := r.Run(app.Query{}, []string{"terraform", "plan"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !selector.called {
+		t.Error("selector.Select was not called")
+	}
+	if executor.profile.Name != "work" {
+		t.Errorf("executor.profile.Name = %q, want %q", executor.profile.Name, "work")
+	}
+}
+
+func TestCommandRunner_ReturnsProfileError(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+
+	executorErr := errors.New("exit status 2")
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	executor := &mockCommandExecutor{err: executorErr}
+
+	r := app.NewCommandRunner(loader, &mockSelector{}, executor)
+	err := r.Run(app.Query{}, []string{"false"})
+
+	var profileErr *app.ProfileError
+	if !errors.As(err, &profileErr) {
+		t.Fatalf("err should be *ProfileError, got %T", err)
+	}
+	if !errors.Is(err, executorErr) {
+		t.Errorf("err = %v, want %v", err, executorErr)
+	}
+}
@@ -0,0 +1,26 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// ApplyGitSettings は p の git 設定 (user.name、user.email、core.sshCommand) を dir のリポジトリに書き込む。
+// switch と audit --fix で共有する。キーごとに書き込み、失敗したキーのエラーをまとめて返す。
+func ApplyGitSettings(w GitConfigWriter, dir string, p domain.Profile) error {
+	var errs []error
+	for _, s := range p.GitSettings() {
+		var err error
+		if s.Value == "" {
+			err = w.UnsetLocalConfig(dir, s.Key)
+		} else {
+			err = w.SetLocalConfig(dir, s.Key, s.Value)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Key, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,53 @@
+package app_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/app"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// failingKeyWriter は failKey の書き込みだけを失敗させる。
+type failingKeyWriter struct {
+	mockGitWriter
+	failKey string
+}
+
+func (m *failingKeyWriter) SetLocalConfig(dir, key, value string) error {
+	if key == m.failKey {
+		return errors.New("could not lock config file")
+	}
+	return m.mockGitWriter.SetLocalConfig(dir, key, value)
+}
+
+func TestApplyGitSettings(t *testing.T) {
+	p := domain.Profile{Name: "work", GitConfigName: "Work", GitConfigEmail: "work@example.com"}
+	w := &mockGitWriter{config: map[string]map[string]string{
+		"/repo": {"core.sshCommand": "ssh -i /keys/id_personal -o IdentitiesOnly=yes"},
+	}}
+
+	if err := app.ApplyGitSettings(w, "/repo", p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"user.name": "Work", "user.email": "work@example.com"}
+	if got := w.config["/repo"]; len(got) != len(want) || got["user.name"] != want["user.name"] || got["user.email"] != want["user.email"] {
+		t.Errorf("config = %v, want %v", got, want)
+	}
+}
+
+func TestApplyGitSettings_ContinuesAfterError(t *testing.T) {
+	p := domain.Profile{Name: "work", GitConfigName: "Work", GitConfigEmail: "work@example.com", SSHIdentity: "/keys/id_work"}
+	w := &failingKeyWriter{mockGitWriter: mockGitWriter{config: map[string]map[string]string{}}, failKey: "user.name"}
+
+	err := app.ApplyGitSettings(w, "/repo", p)
+	if err == nil || !strings.Contains(err.Error(), "user.name: could not lock config file") {
+		t.Fatalf("err = %v, want user.name error", err)
+	}
+	// 失敗したキー以外は書き込む
+	got := w.config["/repo"]
+	if got["user.email"] != "work@example.com" || got["core.sshCommand"] != p.SSHCommand() {
+		t.Errorf("config = %v", got)
+	}
+}
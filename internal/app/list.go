@@ -6,6 +6,7 @@ import (
 	"io"
 	"strings"
 	"sync"
+	"text/tabwriter"
 
 	"github.com/charmbracelet/lipgloss"
 
@@ -30,14 +31,20 @@ type ProfileResult struct {
 	Profile  domain.Profile
 	Username string
 	Output   string
-	Err      error
+	// Rows は Output の代わりに列をそろえて出力する表 (先頭は見出し)
+	Rows [][]string
+	Err  error
 }
 
-func (l *Lister) List(args []string, w io.Writer) error {
+// List は filter に一致するプロファイルごとに gh repo list を並行して実行し、結果を w に書き出す。
+func (l *Lister) List(args []string, filter domain.TagFilter, w io.Writer) error {
 	profiles, err := l.loader.Load()
 	if err != nil {
 		return err
 	}
+	if profiles, err = filter.Apply(profiles); err != nil {
+		return err
+	}
 
 	results := make([]ProfileResult, len(profiles))
 
@@ -48,7 +55,7 @@ func (l *Lister) List(args []string, w io.Writer) error {
 			defer wg.Done()
 			r := ProfileResult{Profile: prof}
 
-			username, err := l.resolver.ResolveGitHubUser(prof.GHConfigDir)
+			username, err := l.resolver.ResolveGitHubUser(prof)
 			if err != nil {
 				r.Err = err
 				results[idx] = r
@@ -56,8 +63,8 @@ func (l *Lister) List(args []string, w io.Writer) error {
 			}
 			r.Username = username
 
-			repoArgs := append([]string{"list"}, args...)
-			output, err := l.executor.ExecRepoCapture(prof, repoArgs)
+			repoArgs := append([]string{"repo", "list"}, args...)
+			output, err := l.executor.ExecCapture(prof, repoArgs)
 			if err != nil {
 				r.Err = err
 				results[idx] = r
@@ -100,6 +107,15 @@ func FormatResults(results []ProfileResult, w io.Writer) {
 			continue
 		}
 
+		if len(r.Rows) > 0 {
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			for _, row := range r.Rows {
+				_, _ = fmt.Fprintln(tw, strings.Join(row, "\t"))
+			}
+			_ = tw.Flush()
+			continue
+		}
+
 		output := strings.TrimRight(r.Output, "\n")
 		if output == "" {
 			_, _ = fmt.Fprintln(w, "No repositories")
@@ -116,5 +132,5 @@ func authHint(err error, profile domain.Profile) string {
 	if !errors.As(err, &checker) || !checker.IsAuthError() {
 		return ""
 	}
-	return fmt.Sprintf("hint: GH_CONFIG_DIR=%s gh auth login", profile.GHConfigDir)
+	return "hint: " + profile.AuthLoginCommand()
 }
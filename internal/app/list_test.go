@@ -17,11 +17,11 @@ type mockResolver struct {
 	err   map[string]error
 }
 
-func (m *mockResolver) ResolveGitHubUser(ghConfigDir string) (string, error) {
-	if e, ok := m.err[ghConfigDir]; ok {
+func (m *mockResolver) ResolveGitHubUser(profile domain.Profile) (string, error) {
+	if e, ok := m.err[profile.GHConfigDir]; ok {
 		return "", e
 	}
-	return m.users[ghConfigDir], nil
+	return m.users[profile.GHConfigDir], nil
 }
 
 // --- mock for GHExecutor (capture) ---
@@ -31,11 +31,11 @@ type mockCaptureExecutor struct {
 	errs    map[string]error
 }
 
-func (m *mockCaptureExecutor) ExecRepo(_ domain.Profile, _ []string) error {
+func (m *mockCaptureExecutor) Exec(_ domain.Profile, _ []string) error {
 	return nil
 }
 
-func (m *mockCaptureExecutor) ExecRepoCapture(profile domain.Profile, _ []string) (string, error) {
+func (m *mockCaptureExecutor) ExecCapture(profile domain.Profile, _ []string) (string, error) {
 	if e, ok := m.errs[profile.GHConfigDir]; ok {
 		return "", e
 	}
@@ -66,7 +66,7 @@ func TestList_MultipleProfiles(t *testing.T) {
 
 	lister := app.NewLister(loader, executor, resolver)
 	var buf bytes.Buffer
-	err := lister.List(nil, &buf)
+	err := lister.List(nil, domain.TagFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -120,7 +120,7 @@ func TestList_ProfileError_ContinuesOthers(t *testing.T) {
 
 	lister := app.NewLister(loader, executor, resolver)
 	var buf bytes.Buffer
-	err := lister.List(nil, &buf)
+	err := lister.List(nil, domain.TagFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -163,7 +163,7 @@ func TestList_ResolverError_ContinuesOthers(t *testing.T) {
 
 	lister := app.NewLister(loader, executor, resolver)
 	var buf bytes.Buffer
-	err := lister.List(nil, &buf)
+	err := lister.List(nil, domain.TagFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -195,7 +195,7 @@ func TestList_EmptyOutput_ShowsNoRepositories(t *testing.T) {
 
 	lister := app.NewLister(loader, executor, resolver)
 	var buf bytes.Buffer
-	err := lister.List(nil, &buf)
+	err := lister.List(nil, domain.TagFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -214,7 +214,7 @@ func TestList_LoaderError(t *testing.T) {
 
 	lister := app.NewLister(loader, executor, resolver)
 	var buf bytes.Buffer
-	err := lister.List(nil, &buf)
+	err := lister.List(nil, domain.TagFilter{}, &buf)
 	if !errors.Is(err, loaderErr) {
 		t.Errorf("err = %v, want %v", err, loaderErr)
 	}
@@ -238,16 +238,16 @@ func TestList_ArgsPassedToExecutor(t *testing.T) {
 	lister := app.NewLister(loader, executor, resolver)
 	var buf bytes.Buffer
 	args := []string{"--limit", "5"}
-	err := lister.List(args, &buf)
+	err := lister.List(args, domain.TagFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// argsが "list" + 渡した引数として転送されること
-	if len(capturedArgs) < 1 || capturedArgs[0] != "list" {
-		t.Errorf("first arg should be 'list', got: %v", capturedArgs)
+	// argsが "repo list" + 渡した引数として転送されること
+	if len(capturedArgs) < 2 || capturedArgs[0] != "repo" || capturedArgs[1] != "list" {
+		t.Errorf("args should start with 'repo list', got: %v", capturedArgs)
 	}
-	if len(capturedArgs) < 3 || capturedArgs[1] != "--limit" || capturedArgs[2] != "5" {
+	if len(capturedArgs) < 4 || capturedArgs[2] != "--limit" || capturedArgs[3] != "5" {
 		t.Errorf("args should contain --limit 5, got: %v", capturedArgs)
 	}
 }
@@ -283,6 +283,24 @@ func TestFormatResults_AuthErrorShowsHint(t *testing.T) {
 	}
 }
 
+func TestFormatResults_AuthErrorHintIncludesEnterpriseHost(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/home/user/.config/gh-work", Host: "ghe.example.com"}
+	results := []app.ProfileResult{
+		{
+			Profile: work,
+			Err:     &mockAuthError{msg: "HTTP 401: Bad credentials"},
+		},
+	}
+
+	var buf bytes.Buffer
+	app.FormatResults(results, &buf)
+	out := buf.String()
+
+	if !strings.Contains(out, "hint: GH_CONFIG_DIR=/home/user/.config/gh-work gh auth login --hostname ghe.example.com") {
+		t.Errorf("output should contain auth hint with hostname, got:\n%s", out)
+	}
+}
+
 func TestFormatResults_NonAuthErrorNoHint(t *testing.T) {
 	work := domain.Profile{Name: "work", GHConfigDir: "/home/user/.config/gh-work"}
 	results := []app.ProfileResult{
@@ -307,11 +325,40 @@ type argsCapturingExecutor struct {
 	capturedArgs *[]string
 }
 
-func (m *argsCapturingExecutor) ExecRepo(_ domain.Profile, _ []string) error {
+func (m *argsCapturingExecutor) Exec(_ domain.Profile, _ []string) error {
 	return nil
 }
 
-func (m *argsCapturingExecutor) ExecRepoCapture(_ domain.Profile, args []string) (string, error) {
+func (m *argsCapturingExecutor) ExecCapture(_ domain.Profile, args []string) (string, error) {
 	*m.capturedArgs = args
 	return m.output, nil
 }
+
+func TestList_TagFilter(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Tags: []string{"work"}}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Tags: []string{"personal"}}
+
+	loader := &mockLoader{profiles: []domain.Profile{work, personal}}
+	executor := &mockCaptureExecutor{
+		outputs: map[string]string{
+			"/path/work":     "octocat-work/project-a\tpublic\t2026-02-10T12:00:00Z\n",
+			"/path/personal": "octocat/dotfiles\tpublic\t2026-01-30T07:52:58Z\n",
+		},
+		errs: map[string]error{},
+	}
+	resolver := &mockResolver{users: map[string]string{}, err: map[string]error{}}
+	lister := app.NewLister(loader, executor, resolver)
+
+	var buf bytes.Buffer
+	if err := lister.List(nil, domain.TagFilter{Exclude: []string{"personal"}}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); !strings.Contains(out, "project-a") || strings.Contains(out, "dotfiles") {
+		t.Errorf("output should only contain work repos, got:\n%s", out)
+	}
+
+	err := lister.List(nil, domain.TagFilter{Include: []string{"wrok"}}, &buf)
+	if !errors.Is(err, domain.ErrUnknownTag) {
+		t.Errorf("err = %v, want %v", err, domain.ErrUnknownTag)
+	}
+}
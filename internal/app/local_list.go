@@ -2,23 +2,51 @@ package app
 
 import (
 	"errors"
+	"fmt"
 	"io"
+	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
+// LocalRepo は root 配下のリポジトリ。owner/repo と host は origin があれば origin の値。
 type LocalRepo struct {
-	Profile string `json:"profile"`
-	Owner   string `json:"owner"`
-	Repo    string `json:"repo"`
+	Profile   string `json:"profile"`
+	Owner     string `json:"owner"`
+	Repo      string `json:"repo"`
+	Host      string `json:"host"`
+	Path      string `json:"path"`
+	RemoteURL string `json:"remote_url,omitempty"`
+	// PathMismatch はパスから読み取れる owner/repo が origin と食い違うことを表す
+	PathMismatch bool `json:"path_mismatch,omitempty"`
+	// Status は作業ツリーの状態。bare リポジトリや読み取れなかったリポジトリでは省く
+	Status *domain.WorkStatus `json:"status,omitempty"`
 }
 
+func newLocalRepo(prof domain.Profile, dir domain.RepoDir, status *domain.WorkStatus) LocalRepo {
+	actual := dir.Actual()
+	return LocalRepo{
+		Profile:      prof.Name,
+		Owner:        actual.Owner,
+		Repo:         actual.Repo,
+		Host:         actual.Host,
+		Path:         dir.Path,
+		RemoteURL:    dir.Origin.URL,
+		PathMismatch: dir.Mismatch(),
+		Status:       status,
+	}
+}
+
+// statusWorkers はリポジトリの状態を同時に読み取る数の上限。lls と audit で使う。
+const statusWorkers = 8
+
 type LocalLister struct {
-	loader   ConfigLoader
-	resolver UserResolver
-	scanner  DirScanner
+	loader    ConfigLoader
+	resolver  UserResolver
+	scanner   DirScanner
+	inspector WorkTreeInspector
 }
 
 func NewLocalLister(loader ConfigLoader, resolver UserResolver, scanner DirScanner) *LocalLister {
@@ -29,89 +57,246 @@ func NewLocalLister(loader ConfigLoader, resolver UserResolver, scanner DirScann
 	}
 }
 
-func (l *LocalLister) ListLocal(w io.Writer) error {
+// SetInspector は作業ツリーの状態の読み取りに使う inspector を設定する。
+// 設定すると各リポジトリのブランチや未 push のコミットなどを表示し、StatusFilter で絞り込める。
+func (l *LocalLister) SetInspector(inspector WorkTreeInspector) {
+	l.inspector = inspector
+}
+
+// ListLocal は filter に一致するプロファイルの root 配下のリポジトリのうち status に一致するものを w に書き出す。
+func (l *LocalLister) ListLocal(filter domain.TagFilter, status domain.StatusFilter, w io.Writer) error {
 	profiles, err := l.loader.Load()
 	if err != nil {
 		return err
 	}
-
-	results := make([]ProfileResult, len(profiles))
-
-	var wg sync.WaitGroup
-	for i, p := range profiles {
-		wg.Add(1)
-		go func(idx int, prof domain.Profile) {
-			defer wg.Done()
-			results[idx] = l.scanProfile(prof)
-		}(i, p)
+	if profiles, err = filter.Apply(profiles); err != nil {
+		return err
 	}
-	wg.Wait()
 
+	scans := l.scanAll(profiles, true)
+	results := make([]ProfileResult, len(scans))
+	for i, s := range scans {
+		results[i] = l.result(s, status)
+	}
 	FormatResults(results, w)
 	return nil
 }
 
-func (l *LocalLister) ListLocalProfile(prof domain.Profile, w io.Writer) error {
-	r := l.scanProfile(prof)
-	FormatResults([]ProfileResult{r}, w)
+func (l *LocalLister) ListLocalProfile(prof domain.Profile, status domain.StatusFilter, w io.Writer) error {
+	scans := l.scanAll([]domain.Profile{prof}, true)
+	FormatResults([]ProfileResult{l.result(scans[0], status)}, w)
 	return nil
 }
 
-func (l *LocalLister) CollectLocalRepos(profiles []domain.Profile) []LocalRepo {
-	reposByIdx := make([][]LocalRepo, len(profiles))
+// CollectLocalRepos は profiles のうち filter に一致するものの root 配下のリポジトリのうち status に一致するものを集める。
+func (l *LocalLister) CollectLocalRepos(profiles []domain.Profile, filter domain.TagFilter, status domain.StatusFilter) ([]LocalRepo, error) {
+	profiles, err := filter.Apply(profiles)
+	if err != nil {
+		return nil, err
+	}
+
+	var all []LocalRepo
+	for _, s := range l.scanAll(profiles, false) {
+		for i, r := range s.repos {
+			if status.Match(s.statuses[i]) {
+				all = append(all, newLocalRepo(s.profile, r, s.statuses[i]))
+			}
+		}
+	}
+	return all, nil
+}
+
+// profileScan は1つのプロファイルの root 配下を探した結果。
+type profileScan struct {
+	profile  domain.Profile
+	username string
+	repos    []domain.RepoDir
+	// statuses は repos と同じ順の作業ツリーの状態。読み取れなかったリポジトリは nil
+	statuses []*domain.WorkStatus
+	err      error
+}
+
+// scanAll は profiles の root 配下をプロファイルごとに並行して探し、
+// inspector があれば見つかったすべてのリポジトリの状態を statusWorkers 個のワーカーで読み取る。
+func (l *LocalLister) scanAll(profiles []domain.Profile, resolveUser bool) []profileScan {
+	scans := make([]profileScan, len(profiles))
 
 	var wg sync.WaitGroup
 	for i, p := range profiles {
 		wg.Add(1)
 		go func(idx int, prof domain.Profile) {
 			defer wg.Done()
-			if prof.Root == "" {
-				return
-			}
-			repos, err := l.scanner.ScanLocalRepos(prof.Root)
-			if err != nil {
-				return
-			}
-			for _, r := range repos {
-				parts := strings.SplitN(r, "/", 2)
-				if len(parts) == 2 {
-					reposByIdx[idx] = append(reposByIdx[idx], LocalRepo{
-						Profile: prof.Name,
-						Owner:   parts[0],
-						Repo:    parts[1],
-					})
+			s := profileScan{profile: prof}
+			if resolveUser {
+				if username, err := l.resolver.ResolveGitHubUser(prof); err == nil {
+					s.username = username
 				}
 			}
+			if prof.Root == "" {
+				s.err = errors.New("root not configured")
+			} else {
+				s.repos, s.err = scanRoot(l.scanner, prof)
+			}
+			s.statuses = make([]*domain.WorkStatus, len(s.repos))
+			scans[idx] = s
 		}(i, p)
 	}
 	wg.Wait()
 
-	var all []LocalRepo
-	for _, repos := range reposByIdx {
-		all = append(all, repos...)
+	if l.inspector != nil {
+		l.inspectAll(scans)
 	}
-	return all
+	return scans
 }
 
-func (l *LocalLister) scanProfile(prof domain.Profile) ProfileResult {
-	r := ProfileResult{Profile: prof}
+// inspectAll は scans のすべてのリポジトリの作業ツリーの状態を読み取る。bare リポジトリは読み取らない。
+func (l *LocalLister) inspectAll(scans []profileScan) {
+	type job struct{ scan, repo int }
+	var jobs []job
+	for i, s := range scans {
+		for j, r := range s.repos {
+			if !r.Bare {
+				jobs = append(jobs, job{scan: i, repo: j})
+			}
+		}
+	}
 
-	username, err := l.resolver.ResolveGitHubUser(prof.GHConfigDir)
-	if err == nil {
-		r.Username = username
+	parallel(len(jobs), statusWorkers, func(i int) {
+		j := jobs[i]
+		if st, err := l.inspector.WorkStatus(scans[j.scan].repos[j.repo].Path); err == nil {
+			scans[j.scan].statuses[j.repo] = &st
+		}
+	})
+}
+
+// parallel は fn(0) から fn(n-1) までを最大 workers 個のゴルーチンで実行する。
+func parallel(n, workers int, fn func(i int)) {
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(n, workers) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := range n {
+		jobs <- i
 	}
+	close(jobs)
+	wg.Wait()
+}
 
-	if prof.Root == "" {
-		r.Err = errors.New("root not configured")
+// result は1つのプロファイルの結果を表示用にまとめる。
+// inspector があれば、リポジトリごとの状態を列にした表にする。
+func (l *LocalLister) result(s profileScan, status domain.StatusFilter) ProfileResult {
+	r := ProfileResult{Profile: s.profile, Username: s.username, Err: s.err}
+	if s.err != nil {
 		return r
 	}
 
-	repos, err := l.scanner.ScanLocalRepos(prof.Root)
-	if err != nil {
-		r.Err = err
+	if l.inspector == nil {
+		lines := make([]string, len(s.repos))
+		for i, repo := range s.repos {
+			lines[i] = formatLocalRepo(s.profile, repo)
+		}
+		r.Output = strings.Join(lines, "\n")
 		return r
 	}
 
-	r.Output = strings.Join(repos, "\n")
+	for i, repo := range s.repos {
+		if !status.Match(s.statuses[i]) {
+			continue
+		}
+		if r.Rows == nil {
+			r.Rows = [][]string{{"REPO", "BRANCH", "CHANGES", "UPSTREAM", "STASH", "LAST COMMIT"}}
+		}
+		r.Rows = append(r.Rows, append([]string{formatLocalRepo(s.profile, repo)}, statusColumns(repo, s.statuses[i])...))
+	}
 	return r
 }
+
+// statusColumns は作業ツリーの状態を表の列にする。
+func statusColumns(repo domain.RepoDir, st *domain.WorkStatus) []string {
+	switch {
+	case repo.Bare:
+		return []string{"(bare)", "-", "-", "-", "-"}
+	case st == nil:
+		return []string{"?", "?", "?", "?", "?"}
+	}
+
+	branch := st.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+
+	var changes []string
+	if st.Dirty > 0 {
+		changes = append(changes, fmt.Sprintf("%d changed", st.Dirty))
+	}
+	if st.Untracked > 0 {
+		changes = append(changes, fmt.Sprintf("%d untracked", st.Untracked))
+	}
+	if len(changes) == 0 {
+		changes = []string{"-"}
+	}
+
+	var upstream []string
+	switch {
+	case st.Upstream == "":
+		upstream = []string{"none"}
+	case st.Ahead == 0 && st.Behind == 0:
+		upstream = []string{"up to date"}
+	default:
+		if st.Ahead > 0 {
+			upstream = append(upstream, fmt.Sprintf("%d ahead", st.Ahead))
+		}
+		if st.Behind > 0 {
+			upstream = append(upstream, fmt.Sprintf("%d behind", st.Behind))
+		}
+	}
+
+	stashes := "-"
+	if st.Stashes > 0 {
+		stashes = strconv.Itoa(st.Stashes)
+	}
+	lastCommit := "-"
+	if !st.LastCommit.IsZero() {
+		lastCommit = st.LastCommit.Local().Format("2006-01-02 15:04")
+	}
+	return []string{branch, strings.Join(changes, ", "), strings.Join(upstream, ", "), stashes, lastCommit}
+}
+
+// formatLocalRepo はリポジトリを1行で表す。パスが origin と食い違えば origin を併記する。
+func formatLocalRepo(prof domain.Profile, repo domain.RepoDir) string {
+	if !repo.Mismatch() {
+		return repo.FullName()
+	}
+	origin := repo.Origin.FullName()
+	if !strings.EqualFold(repo.Origin.Host, prof.HostName()) {
+		origin = repo.Origin.Host + "/" + origin
+	}
+	return fmt.Sprintf("%s (origin: %s)", repo.FullName(), origin)
+}
+
+// scanRoot は prof の root 配下のリポジトリを返す。
+// layout に {host} があれば、root を共有するほかのホストのリポジトリは除く。
+// layout に {host} がなければ、置き場所の host はプロファイルのホストとみなす。
+func scanRoot(scanner DirScanner, prof domain.Profile) ([]domain.RepoDir, error) {
+	repos, err := scanner.ScanLocalRepos(prof.Root, prof.ScanOptions())
+	if err != nil {
+		return nil, err
+	}
+	own := repos[:0]
+	for _, r := range repos {
+		if r.Host == "" {
+			r.Host = prof.HostName()
+		}
+		if strings.EqualFold(r.Host, prof.HostName()) {
+			own = append(own, r)
+		}
+	}
+	return own, nil
+}
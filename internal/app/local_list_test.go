@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"errors"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/sarrrrry/gh-mrepo/internal/app"
 	"github.com/sarrrrry/gh-mrepo/internal/domain"
@@ -13,15 +15,49 @@ import (
 // --- mock for DirScanner ---
 
 type mockScanner struct {
-	repos map[string][]string // root -> repos
-	errs  map[string]error
+	repos   map[string][]string // root -> "owner/repo" または "host/owner/repo"
+	origins map[string]string   // リポジトリのパス -> remote.origin.url
+	errs    map[string]error
 }
 
-func (m *mockScanner) ScanLocalRepos(root string) ([]string, error) {
+func (m *mockScanner) ScanLocalRepos(root string, _ domain.ScanOptions) ([]domain.RepoDir, error) {
 	if e, ok := m.errs[root]; ok {
 		return nil, e
 	}
-	return m.repos[root], nil
+	var repos []domain.RepoDir
+	for _, r := range m.repos[root] {
+		dir := domain.RepoDir{Path: root + "/" + r}
+		parts := strings.Split(r, "/")
+		if len(parts) == 3 {
+			dir.RepoRef = domain.RepoRef{Host: parts[0], Owner: parts[1], Repo: parts[2]}
+		} else {
+			dir.RepoRef = domain.RepoRef{Owner: parts[0], Repo: parts[1]}
+		}
+		if raw, ok := m.origins[dir.Path]; ok {
+			dir.Origin, _ = domain.ParseRemote(raw)
+		}
+		repos = append(repos, dir)
+	}
+	return repos, nil
+}
+
+// --- mock for WorkTreeInspector ---
+
+type mockInspector struct {
+	mu       sync.Mutex
+	statuses map[string]domain.WorkStatus // リポジトリのパス -> 状態。ないパスはエラー
+	calls    []string
+}
+
+func (m *mockInspector) WorkStatus(dir string) (domain.WorkStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, dir)
+	st, ok := m.statuses[dir]
+	if !ok {
+		return domain.WorkStatus{}, errors.New("not a git repository")
+	}
+	return st, nil
 }
 
 // --- テストケース ---
@@ -48,7 +84,7 @@ func TestLocalList_MultipleProfiles(t *testing.T) {
 
 	lister := app.NewLocalLister(loader, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocal(&buf)
+	err := lister.ListLocal(domain.TagFilter{}, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -91,7 +127,7 @@ func TestLocalList_EmptyRoot_ShowsError(t *testing.T) {
 
 	lister := app.NewLocalLister(loader, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocal(&buf)
+	err := lister.ListLocal(domain.TagFilter{}, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -125,7 +161,7 @@ func TestLocalList_ScannerError_ContinuesOthers(t *testing.T) {
 
 	lister := app.NewLocalLister(loader, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocal(&buf)
+	err := lister.ListLocal(domain.TagFilter{}, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -162,7 +198,7 @@ func TestLocalList_ResolverError_ContinuesOthers(t *testing.T) {
 
 	lister := app.NewLocalLister(loader, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocal(&buf)
+	err := lister.ListLocal(domain.TagFilter{}, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -192,7 +228,7 @@ func TestLocalList_EmptyDirectory_ShowsNoRepositories(t *testing.T) {
 
 	lister := app.NewLocalLister(loader, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocal(&buf)
+	err := lister.ListLocal(domain.TagFilter{}, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -211,7 +247,7 @@ func TestLocalList_LoaderError(t *testing.T) {
 
 	lister := app.NewLocalLister(loader, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocal(&buf)
+	err := lister.ListLocal(domain.TagFilter{}, domain.StatusFilter{}, &buf)
 	if !errors.Is(err, loaderErr) {
 		t.Errorf("err = %v, want %v", err, loaderErr)
 	}
@@ -235,7 +271,7 @@ func TestListLocalProfile_Normal(t *testing.T) {
 
 	lister := app.NewLocalLister(nil, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocalProfile(work, &buf)
+	err := lister.ListLocalProfile(work, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -260,7 +296,7 @@ func TestListLocalProfile_EmptyRoot(t *testing.T) {
 
 	lister := app.NewLocalLister(nil, resolver, scanner)
 	var buf bytes.Buffer
-	err := lister.ListLocalProfile(work, &buf)
+	err := lister.ListLocalProfile(work, domain.StatusFilter{}, &buf)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -286,7 +322,10 @@ func TestCollectLocalRepos_MultipleProfiles(t *testing.T) {
 	}
 
 	lister := app.NewLocalLister(nil, nil, scanner)
-	repos := lister.CollectLocalRepos([]domain.Profile{work, personal})
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work, personal}, domain.TagFilter{}, domain.StatusFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if len(repos) != 3 {
 		t.Fatalf("len = %d, want 3", len(repos))
@@ -301,12 +340,116 @@ func TestCollectLocalRepos_MultipleProfiles(t *testing.T) {
 	}
 }
 
+func TestCollectLocalRepos_SharedRootByHost(t *testing.T) {
+	// ghq 形式の root を共有し、ホストで分ける
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/ghq", Host: "ghe.example.com"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/ghq"}
+
+	scanner := &mockScanner{
+		repos: map[string][]string{
+			"/ghq": {"ghe.example.com/corp/api", "github.com/octocat/dotfiles"},
+		},
+		errs: map[string]error{},
+	}
+
+	lister := app.NewLocalLister(nil, nil, scanner)
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work, personal}, domain.TagFilter{}, domain.StatusFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []app.LocalRepo{
+		{Profile: "work", Owner: "corp", Repo: "api", Host: "ghe.example.com", Path: "/ghq/ghe.example.com/corp/api"},
+		{Profile: "personal", Owner: "octocat", Repo: "dotfiles", Host: "github.com", Path: "/ghq/github.com/octocat/dotfiles"},
+	}
+	if len(repos) != len(want) {
+		t.Fatalf("repos = %+v, want %+v", repos, want)
+	}
+	for i := range want {
+		if repos[i] != want[i] {
+			t.Errorf("repos[%d] = %+v, want %+v", i, repos[i], want[i])
+		}
+	}
+}
+
+func TestCollectLocalRepos_Origin(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work"}
+
+	scanner := &mockScanner{
+		repos: map[string][]string{
+			"/home/work": {"octocat/kept", "octocat/old-name", "octocat/no-remote"},
+		},
+		origins: map[string]string{
+			"/home/work/octocat/kept":     "git@github.com:octocat/kept.git",
+			"/home/work/octocat/old-name": "https://github.com/new-org/new-name.git",
+		},
+		errs: map[string]error{},
+	}
+
+	lister := app.NewLocalLister(nil, nil, scanner)
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work}, domain.TagFilter{}, domain.StatusFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := []app.LocalRepo{
+		{Profile: "work", Owner: "octocat", Repo: "kept", Host: "github.com", Path: "/home/work/octocat/kept",
+			RemoteURL: "git@github.com:octocat/kept.git"},
+		// 名前の変更や移管のあとは origin の owner/repo を使う
+		{Profile: "work", Owner: "new-org", Repo: "new-name", Host: "github.com", Path: "/home/work/octocat/old-name",
+			RemoteURL: "https://github.com/new-org/new-name.git", PathMismatch: true},
+		{Profile: "work", Owner: "octocat", Repo: "no-remote", Host: "github.com", Path: "/home/work/octocat/no-remote"},
+	}
+	if len(repos) != len(want) {
+		t.Fatalf("repos = %+v, want %+v", repos, want)
+	}
+	for i := range want {
+		if repos[i] != want[i] {
+			t.Errorf("repos[%d] = %+v, want %+v", i, repos[i], want[i])
+		}
+	}
+}
+
+func TestListLocalProfile_FlagsPathMismatch(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work"}
+	resolver := &mockResolver{users: map[string]string{}, err: map[string]error{}}
+	scanner := &mockScanner{
+		repos: map[string][]string{"/home/work": {"octocat/kept", "octocat/old-name", "octocat/moved"}},
+		origins: map[string]string{
+			"/home/work/octocat/kept":     "git@github.com:octocat/kept.git",
+			"/home/work/octocat/old-name": "git@github.com:octocat/new-name.git",
+			"/home/work/octocat/moved":    "git@ghe.example.com:octocat/moved.git",
+		},
+		errs: map[string]error{},
+	}
+
+	lister := app.NewLocalLister(nil, resolver, scanner)
+	var buf bytes.Buffer
+	if err := lister.ListLocalProfile(work, domain.StatusFilter{}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"octocat/kept\n",
+		"octocat/old-name (origin: octocat/new-name)",
+		"octocat/moved (origin: ghe.example.com/octocat/moved)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q, got:\n%s", want, out)
+		}
+	}
+}
+
 func TestCollectLocalRepos_EmptyRoot_Skipped(t *testing.T) {
 	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: ""}
 
 	scanner := &mockScanner{repos: map[string][]string{}, errs: map[string]error{}}
 	lister := app.NewLocalLister(nil, nil, scanner)
-	repos := lister.CollectLocalRepos([]domain.Profile{work})
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work}, domain.TagFilter{}, domain.StatusFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if len(repos) != 0 {
 		t.Errorf("repos = %v, want empty", repos)
@@ -327,7 +470,10 @@ func TestCollectLocalRepos_ScannerError_Skipped(t *testing.T) {
 	}
 
 	lister := app.NewLocalLister(nil, nil, scanner)
-	repos := lister.CollectLocalRepos([]domain.Profile{work, personal})
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work, personal}, domain.TagFilter{}, domain.StatusFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if len(repos) != 1 {
 		t.Fatalf("len = %d, want 1", len(repos))
@@ -336,3 +482,138 @@ func TestCollectLocalRepos_ScannerError_Skipped(t *testing.T) {
 		t.Errorf("repos[0].Profile = %q, want %q", repos[0].Profile, "personal")
 	}
 }
+
+func TestLocalList_TagFilter(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work", Tags: []string{"work"}}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/home/personal", Tags: []string{"personal"}}
+
+	loader := &mockLoader{profiles: []domain.Profile{work, personal}}
+	resolver := &mockResolver{users: map[string]string{}, err: map[string]error{}}
+	scanner := &mockScanner{
+		repos: map[string][]string{
+			"/home/work":     {"octocat-work/project-a"},
+			"/home/personal": {"octocat/dotfiles"},
+		},
+		errs: map[string]error{},
+	}
+	lister := app.NewLocalLister(loader, resolver, scanner)
+
+	var buf bytes.Buffer
+	if err := lister.ListLocal(domain.TagFilter{Include: []string{"personal"}}, domain.StatusFilter{}, &buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out := buf.String(); strings.Contains(out, "project-a") || !strings.Contains(out, "dotfiles") {
+		t.Errorf("output should only contain personal repos, got:\n%s", out)
+	}
+
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work, personal}, domain.TagFilter{Include: []string{"work"}}, domain.StatusFilter{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repos) != 1 || repos[0].Profile != "work" {
+		t.Errorf("repos = %+v, want only work", repos)
+	}
+
+	_, err = lister.CollectLocalRepos([]domain.Profile{work, personal}, domain.TagFilter{Include: []string{"work"}, Exclude: []string{"work"}}, domain.StatusFilter{})
+	if !errors.Is(err, domain.ErrNoMatchingProfiles) {
+		t.Errorf("err = %v, want %v", err, domain.ErrNoMatchingProfiles)
+	}
+}
+
+func TestListLocalProfile_WorkStatus(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work"}
+	resolver := &mockResolver{users: map[string]string{}, err: map[string]error{}}
+	scanner := &mockScanner{
+		repos: map[string][]string{"/home/work": {"octocat/clean", "octocat/dirty", "octocat/broken"}},
+		errs:  map[string]error{},
+	}
+	committed := time.Date(2026, 1, 2, 3, 4, 0, 0, time.Local)
+	inspector := &mockInspector{statuses: map[string]domain.WorkStatus{
+		"/home/work/octocat/clean": {Branch: "main", Upstream: "origin/main", LastCommit: committed},
+		"/home/work/octocat/dirty": {Branch: "topic", Upstream: "origin/topic", Dirty: 2, Untracked: 1, Ahead: 3, Behind: 1, Stashes: 2, LastCommit: committed},
+	}}
+
+	lister := app.NewLocalLister(nil, resolver, scanner)
+	lister.SetInspector(inspector)
+
+	t.Run("列で表示する", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := lister.ListLocalProfile(work, domain.StatusFilter{}, &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := buf.String()
+		for _, want := range []string{
+			"REPO            BRANCH  CHANGES                 UPSTREAM           STASH  LAST COMMIT\n",
+			"octocat/clean   main    -                       up to date         -      2026-01-02 03:04\n",
+			"octocat/dirty   topic   2 changed, 1 untracked  3 ahead, 1 behind  2      2026-01-02 03:04\n",
+			"octocat/broken  ?       ?                       ?                  ?      ?\n",
+		} {
+			if !strings.Contains(out, want) {
+				t.Errorf("output missing %q, got:\n%s", want, out)
+			}
+		}
+	})
+
+	t.Run("--dirty で絞り込む", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := lister.ListLocalProfile(work, domain.StatusFilter{Dirty: true}, &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		out := buf.String()
+		if !strings.Contains(out, "octocat/dirty") || strings.Contains(out, "octocat/clean") || strings.Contains(out, "octocat/broken") {
+			t.Errorf("output should only list octocat/dirty, got:\n%s", out)
+		}
+	})
+
+	t.Run("一致しなければリポジトリなし", func(t *testing.T) {
+		clean := domain.Profile{Name: "clean", GHConfigDir: "/path/work", Root: "/home/work"}
+		lister := app.NewLocalLister(nil, resolver, &mockScanner{
+			repos: map[string][]string{"/home/work": {"octocat/clean"}},
+			errs:  map[string]error{},
+		})
+		lister.SetInspector(inspector)
+		var buf bytes.Buffer
+		if err := lister.ListLocalProfile(clean, domain.StatusFilter{Ahead: true}, &buf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !strings.Contains(buf.String(), "No repositories") {
+			t.Errorf("output = %q, want No repositories", buf.String())
+		}
+	})
+}
+
+func TestCollectLocalRepos_WorkStatus(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/work"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/home/personal"}
+	scanner := &mockScanner{
+		repos: map[string][]string{
+			"/home/work":     {"octocat/a", "octocat/b", "octocat/c"},
+			"/home/personal": {"octocat/d"},
+		},
+		errs: map[string]error{},
+	}
+	inspector := &mockInspector{statuses: map[string]domain.WorkStatus{
+		"/home/work/octocat/a":     {Branch: "main", Upstream: "origin/main"},
+		"/home/work/octocat/b":     {Branch: "main", Upstream: "origin/main", Ahead: 1},
+		"/home/work/octocat/c":     {Branch: "main", Upstream: "origin/main", Dirty: 1},
+		"/home/personal/octocat/d": {Branch: "main", Upstream: "origin/main", Ahead: 4},
+	}}
+
+	lister := app.NewLocalLister(nil, nil, scanner)
+	lister.SetInspector(inspector)
+
+	repos, err := lister.CollectLocalRepos([]domain.Profile{work, personal}, domain.TagFilter{}, domain.StatusFilter{Ahead: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(repos) != 2 || repos[0].Repo != "b" || repos[1].Repo != "d" {
+		t.Fatalf("repos = %+v, want b and d", repos)
+	}
+	if repos[1].Status == nil || repos[1].Status.Ahead != 4 {
+		t.Errorf("repos[1].Status = %+v, want 4 ahead", repos[1].Status)
+	}
+	// すべてのリポジトリの状態を一度ずつ読み取る
+	if len(inspector.calls) != 4 {
+		t.Errorf("WorkStatus calls = %v, want 4", inspector.calls)
+	}
+}
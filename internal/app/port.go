@@ -10,15 +10,53 @@ type ProfileSelector interface {
 	Select(profiles []domain.Profile) (domain.Profile, error)
 }
 
+// GHExecutor はプロファイルの環境で gh コマンドを実行する。args はサブコマンドを含む。
 type GHExecutor interface {
-	ExecRepo(profile domain.Profile, args []string) error
-	ExecRepoCapture(profile domain.Profile, args []string) (string, error)
+	Exec(profile domain.Profile, args []string) error
+	ExecCapture(profile domain.Profile, args []string) (string, error)
+}
+
+// CommandExecutor はプロファイルの環境で任意のコマンドを実行する。
+type CommandExecutor interface {
+	ExecCommand(profile domain.Profile, argv []string) error
 }
 
 type UserResolver interface {
-	ResolveGitHubUser(ghConfigDir string) (string, error)
+	ResolveGitHubUser(profile domain.Profile) (string, error)
 }
 
 type DirScanner interface {
-	ScanLocalRepos(root string) ([]string, error)
+	ScanLocalRepos(root string, opts domain.ScanOptions) ([]domain.RepoDir, error)
+}
+
+// GitProtocolResolver はプロファイルの hosts.yml に設定された git_protocol を返す。未設定なら空文字列。
+type GitProtocolResolver interface {
+	ResolveGitProtocol(profile domain.Profile) (string, error)
+}
+
+// GitInspector はローカルリポジトリの git 設定を読み取る。
+type GitInspector interface {
+	TopLevel(dir string) (string, error)
+	LocalConfig(dir, key string) (string, error)
+}
+
+// GitConfigWriter はリポジトリローカルの git 設定を書き換える。
+type GitConfigWriter interface {
+	SetLocalConfig(dir, key, value string) error
+	UnsetLocalConfig(dir, key string) error
+}
+
+// WorkTreeInspector はローカルリポジトリの作業ツリーの状態を読み取る。
+type WorkTreeInspector interface {
+	WorkStatus(dir string) (domain.WorkStatus, error)
+}
+
+// ActiveUserResolver は gh でグローバルにアクティブなアカウント名を返す。
+type ActiveUserResolver interface {
+	ResolveActiveUser(host string) string
+}
+
+// GHVersioner はインストールされている gh のバージョンを返す。
+type GHVersioner interface {
+	GHVersion() (string, error)
 }
@@ -1,7 +1,7 @@
 package app
 
 import (
-	"fmt"
+	"io"
 
 	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
@@ -19,6 +19,7 @@ type App struct {
 	loader   ConfigLoader
 	selector ProfileSelector
 	executor GHExecutor
+	log      io.Writer
 }
 
 func New(loader ConfigLoader, selector ProfileSelector, executor GHExecutor) *App {
@@ -26,45 +27,54 @@ func New(loader ConfigLoader, selector ProfileSelector, executor GHExecutor) *Ap
 		loader:   loader,
 		selector: selector,
 		executor: executor,
+		log:      io.Discard,
 	}
 }
 
-func (a *App) Run(user string, args []string) error {
+// SetLogOutput はプロファイル選択の根拠の出力先を設定する。
+func (a *App) SetLogOutput(w io.Writer) {
+	a.log = w
+}
+
+func (a *App) Run(q Query, args []string) error {
 	profiles, err := a.loader.Load()
 	if err != nil {
 		return err
 	}
 
-	var selected domain.Profile
-
-	switch {
-	case user != "":
-		p, err := findProfile(profiles, user)
-		if err != nil {
-			return err
-		}
-		selected = p
-	case len(profiles) == 1:
-		selected = profiles[0]
-	default:
-		p, err := a.selector.Select(profiles)
-		if err != nil {
-			return err
-		}
-		selected = p
+	choice, err := ChooseProfile(profiles, q, a.selector)
+	if err != nil {
+		return err
 	}
+	ReportChoice(a.log, choice)
+	selected := choice.Profile
 
-	if err := a.executor.ExecRepo(selected, args); err != nil {
+	if err := a.executor.Exec(selected, ghArgs(args)); err != nil {
 		return &ProfileError{Profile: selected, Err: err}
 	}
 	return nil
 }
 
-func findProfile(profiles []domain.Profile, name string) (domain.Profile, error) {
-	for _, p := range profiles {
-		if p.Name == name {
-			return p, nil
-		}
+// ghCommands は gh のトップレベルコマンド。これ以外で始まる引数は gh repo のサブコマンドとみなす。
+// config と status は gh-mrepo 自身のコマンドなので含めない ("gh mrepo -- config" のように gh のものを実行する)。
+var ghCommands = map[string]bool{
+	"alias": true, "api": true, "attestation": true, "auth": true, "browse": true,
+	"cache": true, "codespace": true, "completion": true, "extension": true,
+	"gist": true, "gpg-key": true, "issue": true, "label": true, "org": true,
+	"pr": true, "project": true, "release": true, "repo": true, "ruleset": true,
+	"run": true, "search": true, "secret": true, "ssh-key": true,
+	"variable": true, "workflow": true,
+}
+
+// ghArgs は gh に渡す引数を返す。
+// "gh mrepo clone owner/repo" のような従来の省略形は "repo" を補う。
+// "--" で始まれば残りをそのまま渡す (gh のエイリアスや拡張機能のコマンド)。
+func ghArgs(args []string) []string {
+	if len(args) > 0 && args[0] == "--" {
+		return args[1:]
+	}
+	if len(args) > 0 && ghCommands[args[0]] {
+		return args
 	}
-	return domain.Profile{}, fmt.Errorf("profile %q not found", name)
+	return append([]string{"repo"}, args...)
 }
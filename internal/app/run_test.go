@@ -1,7 +1,9 @@
 package app_test
 
 import (
+	"bytes"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/sarrrrry/gh-mrepo/internal/app"
@@ -37,14 +39,14 @@ type mockExecutor struct {
 	called  bool
 }
 
-func (m *mockExecutor) ExecRepo(profile domain.Profile, args []string) error {
+func (m *mockExecutor) Exec(profile domain.Profile, args []string) error {
 	m.called = true
 	m.profile = profile
 	m.args = args
 	return m.err
 }
 
-func (m *mockExecutor) ExecRepoCapture(_ domain.Profile, _ []string) (string, error) {
+func (m *mockExecutor) ExecCapture(_ domain.Profile, _ []string) (string, error) {
 	return "", nil
 }
 
@@ -59,7 +61,7 @@ func TestRun_MultipleProfiles_SelectorCalled(t *testing.T) {
 	executor := &mockExecutor{}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("", []string{"clone", "owner/repo"})
+	err := a.Run(app.Query{}, []string{"clone", "owner/repo"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -67,7 +69,7 @@ func TestRun_MultipleProfiles_SelectorCalled(t *testing.T) {
 		t.Error("selector.Select was not called")
 	}
 	if !executor.called {
-		t.Error("executor.ExecRepo was not called")
+		t.Error("executor.Exec was not called")
 	}
 	if executor.profile.Name != "work" {
 		t.Errorf("executor.profile.Name = %q, want %q", executor.profile.Name, "work")
@@ -82,7 +84,7 @@ func TestRun_SingleProfile_SelectorSkipped(t *testing.T) {
 	executor := &mockExecutor{}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("", []string{"clone", "owner/repo"})
+	err := a.Run(app.Query{}, []string{"clone", "owner/repo"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -103,7 +105,7 @@ func TestRun_UserFlag_SpecifiedProfile(t *testing.T) {
 	executor := &mockExecutor{}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("work", []string{"clone", "owner/repo"})
+	err := a.Run(app.Query{Flag: "work"}, []string{"clone", "owner/repo"})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -123,12 +125,38 @@ func TestRun_UserFlag_UnknownProfile(t *testing.T) {
 	executor := &mockExecutor{}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("unknown", []string{"clone", "owner/repo"})
+	err := a.Run(app.Query{Flag: "unknown"}, []string{"clone", "owner/repo"})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
 }
 
+func TestRun_DirectoryUnderRoot_SelectorSkipped(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work", Root: "/home/user/repos/work"}
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/home/user/repos/personal"}
+
+	loader := &mockLoader{profiles: []domain.Profile{work, personal}}
+	selector := &mockSelector{}
+	executor := &mockExecutor{}
+
+	a := app.New(loader, selector, executor)
+	var log bytes.Buffer
+	a.SetLogOutput(&log)
+	err := a.Run(app.Query{Dir: "/home/user/repos/work/acme/api"}, []string{"repo", "view"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if selector.called {
+		t.Error("selector should not be called when the directory matches a root")
+	}
+	if executor.profile.Name != "work" {
+		t.Errorf("executor.profile.Name = %q, want %q", executor.profile.Name, "work")
+	}
+	if !strings.Contains(log.String(), `profile "work"`) {
+		t.Errorf("log should report the chosen profile, got %q", log.String())
+	}
+}
+
 func TestRun_LoaderError(t *testing.T) {
 	loaderErr := errors.New("load failed")
 	loader := &mockLoader{err: loaderErr}
@@ -136,7 +164,7 @@ func TestRun_LoaderError(t *testing.T) {
 	executor := &mockExecutor{}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("", nil)
+	err := a.Run(app.Query{}, nil)
 	if !errors.Is(err, loaderErr) {
 		t.Errorf("err = %v, want %v", err, loaderErr)
 	}
@@ -152,7 +180,7 @@ func TestRun_SelectorError(t *testing.T) {
 	executor := &mockExecutor{}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("", nil)
+	err := a.Run(app.Query{}, nil)
 	if !errors.Is(err, selectorErr) {
 		t.Errorf("err = %v, want %v", err, selectorErr)
 	}
@@ -167,7 +195,7 @@ func TestRun_ExecutorError(t *testing.T) {
 	executor := &mockExecutor{err: executorErr}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("", []string{"clone", "owner/repo"})
+	err := a.Run(app.Query{}, []string{"clone", "owner/repo"})
 	if !errors.Is(err, executorErr) {
 		t.Errorf("err = %v, want %v", err, executorErr)
 	}
@@ -182,7 +210,7 @@ func TestRun_ReturnsProfileError(t *testing.T) {
 	executor := &mockExecutor{err: executorErr}
 
 	a := app.New(loader, selector, executor)
-	err := a.Run("", []string{"clone", "owner/repo"})
+	err := a.Run(app.Query{}, []string{"clone", "owner/repo"})
 
 	var profileErr *app.ProfileError
 	if !errors.As(err, &profileErr) {
@@ -205,16 +233,93 @@ func TestRun_ArgsPassedToExecutor(t *testing.T) {
 
 	a := app.New(loader, selector, executor)
 	args := []string{"clone", "owner/repo", "--depth", "1"}
-	err := a.Run("", args)
+	err := a.Run(app.Query{}, args)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(executor.args) != len(args) {
-		t.Fatalf("executor.args len = %d, want %d", len(executor.args), len(args))
+	// 従来の省略形は "repo" が補われる
+	want := append([]string{"repo"}, args...)
+	if len(executor.args) != len(want) {
+		t.Fatalf("executor.args = %v, want %v", executor.args, want)
 	}
-	for i, a := range args {
+	for i, a := range want {
 		if executor.args[i] != a {
 			t.Errorf("executor.args[%d] = %q, want %q", i, executor.args[i], a)
 		}
 	}
 }
+
+func TestRun_GHSubcommandPassedThrough(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{name: "repo", args: []string{"repo", "clone", "owner/repo"}},
+		{name: "pr", args: []string{"pr", "list", "--state", "open"}},
+		{name: "issue", args: []string{"issue", "create"}},
+		{name: "api", args: []string{"api", "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &mockLoader{profiles: []domain.Profile{work}}
+			executor := &mockExecutor{}
+
+			a := app.New(loader, &mockSelector{}, executor)
+			if err := a.Run(app.Query{}, tt.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(executor.args, " ") != strings.Join(tt.args, " ") {
+				t.Errorf("executor.args = %v, want %v", executor.args, tt.args)
+			}
+		})
+	}
+}
+
+func TestRun_DoubleDashForcesPassthrough(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "拡張機能", args: []string{"--", "dash", "--org", "acme"}, want: []string{"dash", "--org", "acme"}},
+		{name: "エイリアス", args: []string{"--", "co", "123"}, want: []string{"co", "123"}},
+		{name: "gh のコマンド", args: []string{"--", "pr", "list"}, want: []string{"pr", "list"}},
+		{name: "gh config", args: []string{"--", "config", "get", "git_protocol"}, want: []string{"config", "get", "git_protocol"}},
+		{name: "gh status", args: []string{"--", "status", "--org", "acme"}, want: []string{"status", "--org", "acme"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loader := &mockLoader{profiles: []domain.Profile{work}}
+			executor := &mockExecutor{}
+
+			a := app.New(loader, &mockSelector{}, executor)
+			if err := a.Run(app.Query{}, tt.args); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if strings.Join(executor.args, " ") != strings.Join(tt.want, " ") {
+				t.Errorf("executor.args = %v, want %v", executor.args, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_NoArgs_DefaultsToRepo(t *testing.T) {
+	work := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	executor := &mockExecutor{}
+
+	a := app.New(loader, &mockSelector{}, executor)
+	if err := a.Run(app.Query{}, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(executor.args) != 1 || executor.args[0] != "repo" {
+		t.Errorf("executor.args = %v, want [repo]", executor.args)
+	}
+}
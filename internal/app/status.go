@@ -0,0 +1,214 @@
+package app
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// SourceNone はどのルールにも一致しなかったことを表す。
+const SourceNone Source = "none"
+
+var errNoProfileApplies = errors.New("no profile applies to this directory")
+
+// Status はカレントディレクトリに適用されるプロファイルと、実際の git/gh の設定。
+type Status struct {
+	Dir        string      `json:"dir"`
+	Profile    string      `json:"profile,omitempty"`
+	Source     Source      `json:"source"`
+	Detail     string      `json:"detail,omitempty"`
+	Host       string      `json:"host,omitempty"`
+	GitHubUser string      `json:"github_user,omitempty"`
+	ActiveUser string      `json:"active_user,omitempty"`
+	Repo       *RepoConfig `json:"repo,omitempty"`
+	Mismatches []Mismatch  `json:"mismatches"`
+	Warnings   []string    `json:"warnings,omitempty"`
+}
+
+// RepoConfig はリポジトリローカルの git 設定。
+type RepoConfig struct {
+	Path       string `json:"path"`
+	UserName   string `json:"user_name"`
+	UserEmail  string `json:"user_email"`
+	SSHCommand string `json:"ssh_command"`
+	OriginURL  string `json:"origin_url"`
+}
+
+// Mismatch はプロファイルが期待する値と実際の値の不一致。
+type Mismatch struct {
+	Key      string `json:"key"`
+	Expected string `json:"expected"`
+	Actual   string `json:"actual"`
+}
+
+type StatusReporter struct {
+	loader   ConfigLoader
+	resolver UserResolver
+	git      GitInspector
+	active   ActiveUserResolver
+}
+
+func NewStatusReporter(loader ConfigLoader, resolver UserResolver, git GitInspector, active ActiveUserResolver) *StatusReporter {
+	return &StatusReporter{
+		loader:   loader,
+		resolver: resolver,
+		git:      git,
+		active:   active,
+	}
+}
+
+// Status は q.Dir に適用されるプロファイルを決定し、gh と git の設定との不一致を調べる。
+// 対話的な選択は行わない。
+func (s *StatusReporter) Status(q Query) (Status, error) {
+	st := Status{Dir: q.Dir, Source: SourceNone, Mismatches: []Mismatch{}}
+
+	profiles, err := s.loader.Load()
+	if err != nil {
+		return st, err
+	}
+
+	choice, err := ChooseProfile(profiles, q, noSelector{})
+	if errors.Is(err, errNoProfileApplies) {
+		return st, nil
+	}
+	if err != nil {
+		return st, err
+	}
+	p := choice.Profile
+	st.Profile = p.Name
+	st.Source = choice.Source
+	st.Detail = choice.Detail
+	st.Host = p.HostName()
+
+	if user, err := s.resolver.ResolveGitHubUser(p); err != nil {
+		st.Warnings = append(st.Warnings, fmt.Sprintf("github user: %v", err))
+	} else {
+		st.GitHubUser = user
+	}
+
+	st.ActiveUser = s.active.ResolveActiveUser(p.HostName())
+	if st.ActiveUser == "" {
+		st.Warnings = append(st.Warnings, "could not determine the active gh account")
+	} else if st.GitHubUser != "" && st.ActiveUser != st.GitHubUser {
+		st.addMismatch("active_user", st.GitHubUser, st.ActiveUser)
+	}
+
+	top, err := s.git.TopLevel(q.Dir)
+	if err != nil {
+		// gitリポジトリ外
+		return st, nil
+	}
+	repo, err := s.readRepoConfig(top)
+	if err != nil {
+		st.Warnings = append(st.Warnings, fmt.Sprintf("git config: %v", err))
+		return st, nil
+	}
+	st.Repo = &repo
+
+	if p.GitConfigName != "" && repo.UserName != p.GitConfigName {
+		st.addMismatch("user.name", p.GitConfigName, repo.UserName)
+	}
+	if p.GitConfigEmail != "" && repo.UserEmail != p.GitConfigEmail {
+		st.addMismatch("user.email", p.GitConfigEmail, repo.UserEmail)
+	}
+	if repo.SSHCommand != p.SSHCommand() {
+		st.addMismatch("core.sshCommand", p.SSHCommand(), repo.SSHCommand)
+	}
+	if repo.OriginURL != "" {
+		if remote, err := domain.ParseRemote(repo.OriginURL); err == nil && remote.Host != p.HostName() {
+			st.addMismatch("origin.host", p.HostName(), remote.Host)
+		}
+	}
+	return st, nil
+}
+
+func (s *StatusReporter) readRepoConfig(top string) (RepoConfig, error) {
+	repo := RepoConfig{Path: top}
+	fields := []struct {
+		key string
+		dst *string
+	}{
+		{"user.name", &repo.UserName},
+		{"user.email", &repo.UserEmail},
+		{"core.sshCommand", &repo.SSHCommand},
+		{"remote.origin.url", &repo.OriginURL},
+	}
+	for _, f := range fields {
+		v, err := s.git.LocalConfig(top, f.key)
+		if err != nil {
+			return repo, err
+		}
+		*f.dst = v
+	}
+	return repo, nil
+}
+
+func (st *Status) addMismatch(key, expected, actual string) {
+	st.Mismatches = append(st.Mismatches, Mismatch{Key: key, Expected: expected, Actual: actual})
+}
+
+// mismatch は key の不一致を返す。一致している場合は nil を返す。
+func (st Status) mismatch(key string) *Mismatch {
+	for i := range st.Mismatches {
+		if st.Mismatches[i].Key == key {
+			return &st.Mismatches[i]
+		}
+	}
+	return nil
+}
+
+// FormatStatus は Status を人が読む形式で w に出力する。不一致は赤で強調する。
+func FormatStatus(st Status, w io.Writer) {
+	labelStyle := lipgloss.NewStyle().Bold(true)
+	errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+
+	line := func(label, value, key string) {
+		if value == "" {
+			value = dimStyle.Render("(unset)")
+		}
+		if m := st.mismatch(key); m != nil {
+			expected := m.Expected
+			if expected == "" {
+				expected = "(unset)"
+			}
+			value = errorStyle.Render(fmt.Sprintf("%s  ✗ expected %s", value, expected))
+		}
+		_, _ = fmt.Fprintf(w, "%s %s\n", labelStyle.Render(fmt.Sprintf("%-16s", label)), value)
+	}
+
+	if st.Profile == "" {
+		line("Profile", errorStyle.Render("none"), "")
+		return
+	}
+
+	reason := Choice{Source: st.Source, Detail: st.Detail}.Reason()
+	line("Profile", fmt.Sprintf("%s (%s)", st.Profile, reason), "")
+	line("Host", st.Host, "")
+	line("GitHub user", st.GitHubUser, "")
+	line("Active gh user", st.ActiveUser, "active_user")
+
+	if st.Repo != nil {
+		_, _ = fmt.Fprintln(w)
+		line("Repository", st.Repo.Path, "")
+		line("user.name", st.Repo.UserName, "user.name")
+		line("user.email", st.Repo.UserEmail, "user.email")
+		line("core.sshCommand", st.Repo.SSHCommand, "core.sshCommand")
+		line("origin", st.Repo.OriginURL, "origin.host")
+	}
+
+	for _, warn := range st.Warnings {
+		_, _ = fmt.Fprintln(w, errorStyle.Render("warning: "+warn))
+	}
+}
+
+// noSelector は対話的な選択をせずに errNoProfileApplies を返すセレクタ。
+type noSelector struct{}
+
+func (noSelector) Select(_ []domain.Profile) (domain.Profile, error) {
+	return domain.Profile{}, errNoProfileApplies
+}
@@ -0,0 +1,167 @@
+package app_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/app"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// --- mock for GitInspector ---
+
+type mockGit struct {
+	topLevel map[string]string            // dir -> toplevel
+	config   map[string]map[string]string // toplevel -> key -> value
+}
+
+func (m *mockGit) TopLevel(dir string) (string, error) {
+	top, ok := m.topLevel[dir]
+	if !ok {
+		return "", errors.New("not a git repository")
+	}
+	return top, nil
+}
+
+func (m *mockGit) LocalConfig(dir, key string) (string, error) {
+	return m.config[dir][key], nil
+}
+
+// --- mock for ActiveUserResolver ---
+
+type mockActiveUser struct {
+	users map[string]string // host -> user
+}
+
+func (m *mockActiveUser) ResolveActiveUser(host string) string {
+	return m.users[host]
+}
+
+func newStatusFixture() (domain.Profile, *mockResolver, *mockGit) {
+	work := domain.Profile{
+		Name:           "work",
+		GHConfigDir:    "/path/work",
+		Root:           "/home/user/work",
+		GitConfigName:  "Work User",
+		GitConfigEmail: "work@example.com",
+		SSHIdentity:    "/home/user/.ssh/id_work",
+	}
+	resolver := &mockResolver{
+		users: map[string]string{"/path/work": "octocat-work"},
+		err:   map[string]error{},
+	}
+	git := &mockGit{
+		topLevel: map[string]string{"/home/user/work/acme/api/cmd": "/home/user/work/acme/api"},
+		config: map[string]map[string]string{
+			"/home/user/work/acme/api": {
+				"user.name":         "Work User",
+				"user.email":        "work@example.com",
+				"core.sshCommand":   "ssh -i /home/user/.ssh/id_work -o IdentitiesOnly=yes",
+				"remote.origin.url": "git@github.com:acme/api.git",
+			},
+		},
+	}
+	return work, resolver, git
+}
+
+func TestStatus_NoMismatch(t *testing.T) {
+	work, resolver, git := newStatusFixture()
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	active := &mockActiveUser{users: map[string]string{"github.com": "octocat-work"}}
+
+	r := app.NewStatusReporter(loader, resolver, git, active)
+	st, err := r.Status(app.Query{Dir: "/home/user/work/acme/api/cmd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if st.Profile != "work" || st.Source != app.SourceRoot {
+		t.Errorf("profile = %q (%s), want work (root)", st.Profile, st.Source)
+	}
+	if st.GitHubUser != "octocat-work" {
+		t.Errorf("GitHubUser = %q", st.GitHubUser)
+	}
+	if st.Repo == nil || st.Repo.Path != "/home/user/work/acme/api" {
+		t.Fatalf("Repo = %+v", st.Repo)
+	}
+	if st.Repo.OriginURL != "git@github.com:acme/api.git" {
+		t.Errorf("OriginURL = %q", st.Repo.OriginURL)
+	}
+	if len(st.Mismatches) != 0 {
+		t.Errorf("Mismatches = %+v, want none", st.Mismatches)
+	}
+}
+
+func TestStatus_Mismatches(t *testing.T) {
+	work, resolver, git := newStatusFixture()
+	work.Host = "ghe.example.com"
+	cfg := git.config["/home/user/work/acme/api"]
+	cfg["user.email"] = "me@personal.example.com"
+	cfg["core.sshCommand"] = ""
+
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	active := &mockActiveUser{users: map[string]string{"ghe.example.com": "octocat"}}
+
+	r := app.NewStatusReporter(loader, resolver, git, active)
+	st, err := r.Status(app.Query{Dir: "/home/user/work/acme/api/cmd"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := make(map[string]app.Mismatch)
+	for _, m := range st.Mismatches {
+		got[m.Key] = m
+	}
+	for _, key := range []string{"active_user", "user.email", "core.sshCommand", "origin.host"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("mismatch %q not reported, got %+v", key, st.Mismatches)
+		}
+	}
+	if _, ok := got["user.name"]; ok {
+		t.Errorf("user.name should match, got %+v", got["user.name"])
+	}
+	if m := got["user.email"]; m.Expected != "work@example.com" || m.Actual != "me@personal.example.com" {
+		t.Errorf("user.email mismatch = %+v", m)
+	}
+
+	var buf bytes.Buffer
+	app.FormatStatus(st, &buf)
+	if !strings.Contains(buf.String(), "expected work@example.com") {
+		t.Errorf("output should highlight the email mismatch, got:\n%s", buf.String())
+	}
+}
+
+func TestStatus_NoProfileApplies(t *testing.T) {
+	work, resolver, git := newStatusFixture()
+	personal := domain.Profile{Name: "personal", GHConfigDir: "/path/personal", Root: "/home/user/personal"}
+	loader := &mockLoader{profiles: []domain.Profile{work, personal}}
+
+	r := app.NewStatusReporter(loader, resolver, git, &mockActiveUser{})
+	st, err := r.Status(app.Query{Dir: "/tmp"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Profile != "" || st.Source != app.SourceNone {
+		t.Errorf("profile = %q (%s), want none", st.Profile, st.Source)
+	}
+}
+
+func TestStatus_OutsideRepository(t *testing.T) {
+	work, resolver, git := newStatusFixture()
+	loader := &mockLoader{profiles: []domain.Profile{work}}
+	active := &mockActiveUser{users: map[string]string{"github.com": "octocat-work"}}
+
+	r := app.NewStatusReporter(loader, resolver, git, active)
+	st, err := r.Status(app.Query{Flag: "work", Dir: "/home/user"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if st.Source != app.SourceFlag {
+		t.Errorf("Source = %q, want %q", st.Source, app.SourceFlag)
+	}
+	if st.Repo != nil {
+		t.Errorf("Repo = %+v, want nil", st.Repo)
+	}
+}
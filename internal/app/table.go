@@ -0,0 +1,48 @@
+package app
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// tableCell は表の1セル。列の幅は style を適用する前の text で測る。
+type tableCell struct {
+	text  string
+	style *lipgloss.Style
+}
+
+func styled(style lipgloss.Style, text string) tableCell {
+	return tableCell{text: text, style: &style}
+}
+
+// writeTable は rows を2つの空白で区切って列をそろえて w に書き出す。
+// text/tabwriter は ANSI エスケープも幅に数えて列がずれるので、装飾前の見た目の幅でそろえる。
+func writeTable(w io.Writer, rows [][]tableCell) {
+	var widths []int
+	for _, row := range rows {
+		for i, c := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], lipgloss.Width(c.text))
+		}
+	}
+
+	for _, row := range rows {
+		var b strings.Builder
+		for i, c := range row {
+			text := c.text
+			if c.style != nil {
+				text = c.style.Render(text)
+			}
+			b.WriteString(text)
+			if i < len(row)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-lipgloss.Width(c.text)+2))
+			}
+		}
+		_, _ = fmt.Fprintln(w, b.String())
+	}
+}
@@ -0,0 +1,178 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// Account は既存の gh 設定ディレクトリにログイン済みのアカウント。
+type Account struct {
+	GHConfigDir string
+	Host        string
+	User        string
+	// Shared は同じディレクトリ・ホストに他のアカウントもログインしていることを表す。
+	Shared bool
+}
+
+func (a Account) String() string {
+	return fmt.Sprintf("%s@%s (%s)", a.User, a.Host, a.GHConfigDir)
+}
+
+// DiscoverAccounts は既存の gh 設定ディレクトリ (~/.config/gh*, $GH_CONFIG_DIR) を探し、
+// hosts.yml にログイン済みのアカウントを返す。hosts.yml が読めないディレクトリは無視する。
+func DiscoverAccounts(home, ghConfigDirEnv string) []Account {
+	dirs, _ := filepath.Glob(filepath.Join(home, ".config", "gh*"))
+	sort.Strings(dirs)
+	if ghConfigDirEnv != "" {
+		dirs = append([]string{ghConfigDirEnv}, dirs...)
+	}
+
+	seen := make(map[string]bool)
+	var accounts []Account
+	for _, dir := range dirs {
+		dir = filepath.Clean(dir)
+		if seen[dir] || filepath.Base(dir) == "gh-mrepo" {
+			continue
+		}
+		seen[dir] = true
+
+		found, err := accountsIn(dir)
+		if err != nil {
+			continue
+		}
+		accounts = append(accounts, found...)
+	}
+	return accounts
+}
+
+// accountsIn は dir/hosts.yml にログイン済みのアカウントをホスト名順に返す。
+func accountsIn(dir string) ([]Account, error) {
+	hosts, err := ReadHosts(dir)
+	if err != nil {
+		return nil, err
+	}
+	hostNames := make([]string, 0, len(hosts))
+	for host := range hosts {
+		hostNames = append(hostNames, host)
+	}
+	sort.Strings(hostNames)
+
+	var accounts []Account
+	for _, host := range hostNames {
+		users := hosts[host].UserNames()
+		for _, user := range users {
+			accounts = append(accounts, Account{GHConfigDir: dir, Host: host, User: user, Shared: len(users) > 1})
+		}
+	}
+	return accounts, nil
+}
+
+var nonNameChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// SuggestProfiles はアカウントごとにプロファイルの初期値を提案する。
+// パスは ~ から始まる表記で返す。
+func SuggestProfiles(accounts []Account, home string) []domain.Profile {
+	used := make(map[string]bool)
+	profiles := make([]domain.Profile, 0, len(accounts))
+	for _, a := range accounts {
+		name := uniqueName(profileNameFor(a), used)
+		p := domain.Profile{
+			Name:          name,
+			GHConfigDir:   compressHome(a.GHConfigDir, home),
+			Root:          compressHome(filepath.Join(home, "repos", name), home),
+			GitConfigName: a.User,
+		}
+		if a.Host != domain.DefaultHost {
+			p.Host = a.Host
+		} else {
+			p.GitConfigEmail = a.User + "@users.noreply.github.com"
+		}
+		if a.Shared {
+			p.User = a.User
+		}
+		if key := findSSHKey(home, a.User); key != "" {
+			p.SSHIdentity = compressHome(key, home)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
+
+func profileNameFor(a Account) string {
+	name := strings.Trim(nonNameChars.ReplaceAllString(strings.ToLower(a.User), "-"), "-")
+	if name == "" {
+		name = "default"
+	}
+	if a.Host != domain.DefaultHost {
+		name += "-" + strings.Split(a.Host, ".")[0]
+	}
+	return name
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	candidate := name
+	for i := 2; used[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+	used[candidate] = true
+	return candidate
+}
+
+// findSSHKey は ~/.ssh から名前に user を含む秘密鍵 (id_*) を探す。
+func findSSHKey(home, user string) string {
+	matches, _ := filepath.Glob(filepath.Join(home, ".ssh", "id_*"))
+	sort.Strings(matches)
+	lower := strings.ToLower(user)
+	for _, m := range matches {
+		base := strings.ToLower(filepath.Base(m))
+		if strings.HasSuffix(base, ".pub") || !strings.Contains(base, lower) {
+			continue
+		}
+		if info, err := os.Stat(m); err == nil && info.Mode().IsRegular() {
+			return m
+		}
+	}
+	return ""
+}
+
+func compressHome(path, home string) string {
+	if rel, err := filepath.Rel(home, path); err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		if rel == "." {
+			return "~"
+		}
+		return "~/" + filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// RenderConfig はプロファイルから config.toml の内容を生成する。空の項目は書き出さない。
+func RenderConfig(profiles []domain.Profile) []byte {
+	var b strings.Builder
+	b.WriteString("# gh-mrepo configuration\n# See: gh mrepo --help\n")
+	for _, p := range profiles {
+		fmt.Fprintf(&b, "\n[%s]\n", formatKey(p.Name))
+		for _, kv := range []KeyValue{
+			{Key: "gh_config_dir", Value: p.GHConfigDir},
+			{Key: "host", Value: p.Host},
+			{Key: "user", Value: p.User},
+			{Key: "root", Value: p.Root},
+			{Key: "git_config_name", Value: p.GitConfigName},
+			{Key: "git_config_email", Value: p.GitConfigEmail},
+			{Key: "ssh_identity", Value: p.SSHIdentity},
+		} {
+			if kv.Value != "" {
+				fmt.Fprintf(&b, "%s = %s\n", kv.Key, formatString(kv.Value))
+			}
+		}
+		if len(p.Tags) > 0 {
+			fmt.Fprintf(&b, "tags = %s\n", formatStringList(p.Tags))
+		}
+	}
+	return []byte(b.String())
+}
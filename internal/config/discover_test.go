@@ -0,0 +1,113 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func mkGHDir(t *testing.T, dir, hostsYml string) {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if hostsYml != "" {
+		writeHostsYml(t, dir, hostsYml)
+	}
+}
+
+func TestDiscoverAccounts(t *testing.T) {
+	home := t.TempDir()
+	mkGHDir(t, filepath.Join(home, ".config", "gh"), multiAccountHostsYml)
+	mkGHDir(t, filepath.Join(home, ".config", "gh-corp"), `ghe.example.com:
+    user: taro
+`)
+	mkGHDir(t, filepath.Join(home, ".config", "gh-empty"), "")
+	mkGHDir(t, filepath.Join(home, ".config", "gh-mrepo"), "github.com:\n    user: ignored\n")
+	envDir := filepath.Join(home, "elsewhere")
+	mkGHDir(t, envDir, "github.com:\n    user: envuser\n")
+
+	got := config.DiscoverAccounts(home, envDir)
+	want := []config.Account{
+		{GHConfigDir: envDir, Host: "github.com", User: "envuser"},
+		{GHConfigDir: filepath.Join(home, ".config", "gh"), Host: "github.com", User: "octocat", Shared: true},
+		{GHConfigDir: filepath.Join(home, ".config", "gh"), Host: "github.com", User: "octocat-work", Shared: true},
+		{GHConfigDir: filepath.Join(home, ".config", "gh-corp"), Host: "ghe.example.com", User: "taro"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("DiscoverAccounts = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("accounts[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestSuggestProfiles(t *testing.T) {
+	home := t.TempDir()
+	sshDir := filepath.Join(home, ".ssh")
+	if err := os.MkdirAll(sshDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"id_ed25519_octocat-work", "id_ed25519_octocat-work.pub"} {
+		if err := os.WriteFile(filepath.Join(sshDir, name), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	accounts := []config.Account{
+		{GHConfigDir: filepath.Join(home, ".config", "gh"), Host: "github.com", User: "Octocat-Work", Shared: true},
+		{GHConfigDir: filepath.Join(home, ".config", "gh-corp"), Host: "ghe.example.com", User: "taro"},
+		{GHConfigDir: "/opt/gh", Host: "github.com", User: "octocat-work"},
+	}
+	got := config.SuggestProfiles(accounts, home)
+	want := []domain.Profile{
+		{
+			Name: "octocat-work", GHConfigDir: "~/.config/gh", Root: "~/repos/octocat-work",
+			GitConfigName: "Octocat-Work", GitConfigEmail: "Octocat-Work@users.noreply.github.com",
+			SSHIdentity: "~/.ssh/id_ed25519_octocat-work", User: "Octocat-Work",
+		},
+		{
+			Name: "taro-ghe", GHConfigDir: "~/.config/gh-corp", Root: "~/repos/taro-ghe",
+			GitConfigName: "taro", Host: "ghe.example.com",
+		},
+		{
+			Name: "octocat-work-2", GHConfigDir: "/opt/gh", Root: "~/repos/octocat-work-2",
+			GitConfigName: "octocat-work", GitConfigEmail: "octocat-work@users.noreply.github.com",
+			SSHIdentity: "~/.ssh/id_ed25519_octocat-work",
+		},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("SuggestProfiles = %v, want %v", got, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got[i], want[i]) {
+			t.Errorf("profiles[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRenderConfig_RoundTrip(t *testing.T) {
+	profiles := []domain.Profile{
+		{Name: "work", GHConfigDir: "/gh-work", Root: "/repos/work", GitConfigEmail: `a"b@example.com`, Host: "ghe.example.com"},
+		{Name: "personal", GHConfigDir: "/gh", User: "octocat"},
+	}
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := config.NewInitializer().Write(path, config.RenderConfig(profiles)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	loaded, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Load は名前順に返す
+	if len(loaded) != 2 || !reflect.DeepEqual(loaded[0], profiles[1]) || !reflect.DeepEqual(loaded[1], profiles[0]) {
+		t.Errorf("loaded = %+v, want %+v", loaded, profiles)
+	}
+}
@@ -0,0 +1,359 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// document は TOML ファイルを行単位で保持する。
+// 編集対象以外の行には触れないため、コメントや空行、書式がそのまま残る。
+type document struct {
+	lines []string
+}
+
+// docKey はテーブル内のキー定義。start から end (含まない) までの行を占める。
+type docKey struct {
+	path    []string
+	raw     string
+	indent  string
+	comment string
+	start   int
+	end     int
+}
+
+// docTable はテーブル1つ分の範囲。header はヘッダ行 (ルートテーブルは -1)、
+// end は次のテーブルに付くコメントを除いた本文の終端 (含まない)。
+type docTable struct {
+	path   []string
+	header int
+	end    int
+	keys   []docKey
+	// array は [[name]] 形式のテーブル配列の要素であることを表す
+	array bool
+}
+
+var (
+	tableHeaderRe = regexp.MustCompile(`^\s*\[\s*([^\[\]]+?)\s*\]\s*(#.*)?$`)
+	arrayHeaderRe = regexp.MustCompile(`^\s*\[\[\s*([^\[\]]+?)\s*\]\]\s*(#.*)?$`)
+	keyLineRe     = regexp.MustCompile(`^(\s*)((?:"[^"]*"|'[^']*'|[A-Za-z0-9_-]+)(?:\s*\.\s*(?:"[^"]*"|'[^']*'|[A-Za-z0-9_-]+))*)\s*=`)
+	bareKeyRe     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+func parseDocument(data []byte) *document {
+	s := strings.TrimSuffix(string(data), "\n")
+	if s == "" {
+		return &document{}
+	}
+	return &document{lines: strings.Split(s, "\n")}
+}
+
+func (d *document) bytes() []byte {
+	if len(d.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(d.lines, "\n") + "\n")
+}
+
+// tables はドキュメントをテーブル単位に分割する。先頭要素は常にルートテーブル。
+func (d *document) tables() []docTable {
+	tables := []docTable{{header: -1}}
+	for i := 0; i < len(d.lines); i++ {
+		line := d.lines[i]
+		cur := &tables[len(tables)-1]
+
+		if strings.HasPrefix(strings.TrimSpace(line), "[") {
+			t := docTable{header: i}
+			if m := tableHeaderRe.FindStringSubmatch(line); m != nil {
+				t.path = splitDottedKey(m[1])
+			} else if m := arrayHeaderRe.FindStringSubmatch(line); m != nil {
+				t.path, t.array = splitDottedKey(m[1]), true
+			}
+			cur.end = d.attachedCommentStart(i)
+			tables = append(tables, t)
+			continue
+		}
+
+		m := keyLineRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		var sc valueScanner
+		open := sc.feed(line[len(m[0]):])
+		end := i + 1
+		for open && end < len(d.lines) {
+			open = sc.feed(d.lines[end])
+			end++
+		}
+		key := docKey{path: splitDottedKey(m[2]), raw: m[2], indent: m[1], start: i, end: end}
+		if end == i+1 && sc.comment >= 0 {
+			key.comment = line[len(m[0])+sc.comment:]
+		}
+		cur.keys = append(cur.keys, key)
+		i = end - 1
+	}
+	tables[len(tables)-1].end = len(d.lines)
+	return tables
+}
+
+// attachedCommentStart は header 行の直前に空行なしで続くコメント行の先頭を返す。
+func (d *document) attachedCommentStart(header int) int {
+	start := header
+	for start > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[start-1]), "#") {
+		start--
+	}
+	return start
+}
+
+// findTable は path のテーブルを返す。path が空ならルートテーブルを返す。
+func (d *document) findTable(path []string) (docTable, bool) {
+	for _, t := range d.tables() {
+		if len(path) == 0 && t.header < 0 {
+			return t, true
+		}
+		if t.header >= 0 && !t.array && slices.Equal(t.path, path) {
+			return t, true
+		}
+	}
+	return docTable{}, false
+}
+
+// line は key (テーブルのパス + キー) が定義されている行番号 (1始まり) を返す。
+// 見つからなければ親のキーやテーブルの行を探し、それも無ければ 0 を返す。
+func (d *document) line(key []string) int {
+	tables := d.tables()
+	for n := len(key); n > 0; n-- {
+		want := key[:n]
+		for _, t := range tables {
+			if t.header >= 0 && slices.Equal(t.path, want) {
+				return t.header + 1
+			}
+			for _, k := range t.keys {
+				if slices.Equal(slices.Concat(t.path, k.path), want) {
+					return k.start + 1
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// hasTable は path のテーブルが存在するかを返す。
+func (d *document) hasTable(path []string) bool {
+	_, ok := d.findTable(path)
+	return ok
+}
+
+// setKey はテーブル内のキーに TOML 値 value を設定する。
+// 既存のキーは同じ位置で置き換え、無ければテーブル内の最後のキーの後に追加する。
+func (d *document) setKey(table []string, key, value string) error {
+	t, ok := d.findTable(table)
+	if !ok {
+		return fmt.Errorf("table [%s] not found", formatTableName(table))
+	}
+	for _, k := range t.keys {
+		if slices.Equal(k.path, []string{key}) {
+			line := k.indent + k.raw + " = " + value
+			if k.comment != "" {
+				line += " " + k.comment
+			}
+			d.replace(k.start, k.end, line)
+			return nil
+		}
+	}
+	at := t.header + 1
+	indent := ""
+	if n := len(t.keys); n > 0 {
+		at = t.keys[n-1].end
+		indent = t.keys[n-1].indent
+	}
+	d.replace(at, at, indent+formatKey(key)+" = "+value)
+	return nil
+}
+
+// removeKey はテーブルからキーを削除する。キーが無ければ false を返す。
+func (d *document) removeKey(table []string, key string) bool {
+	t, ok := d.findTable(table)
+	if !ok {
+		return false
+	}
+	for _, k := range t.keys {
+		if slices.Equal(k.path, []string{key}) {
+			d.replace(k.start, k.end)
+			return true
+		}
+	}
+	return false
+}
+
+// removeTable は path のテーブルとそのサブテーブルを、直前のコメントごと削除する。
+func (d *document) removeTable(path []string) bool {
+	tables := d.tables()
+	removed := false
+	for i := len(tables) - 1; i >= 0; i-- {
+		t := tables[i]
+		if t.header < 0 || t.array || len(t.path) < len(path) || !slices.Equal(t.path[:len(path)], path) {
+			continue
+		}
+		start := d.attachedCommentStart(t.header)
+		end := t.end
+		// テーブル間の空行が二重にならないよう、末尾の空行も削除する
+		for end < len(d.lines) && strings.TrimSpace(d.lines[end]) == "" {
+			end++
+		}
+		if end == len(d.lines) {
+			for start > 0 && strings.TrimSpace(d.lines[start-1]) == "" {
+				start--
+			}
+		}
+		d.replace(start, end)
+		removed = true
+	}
+	return removed
+}
+
+// appendTable はファイル末尾にテーブルを追加する。
+func (d *document) appendTable(path []string, keys []string, values []string) {
+	if n := len(d.lines); n > 0 && strings.TrimSpace(d.lines[n-1]) != "" {
+		d.lines = append(d.lines, "")
+	}
+	d.lines = append(d.lines, "["+formatTableName(path)+"]")
+	for i, key := range keys {
+		d.lines = append(d.lines, formatKey(key)+" = "+values[i])
+	}
+}
+
+// replace は start から end (含まない) までの行を lines で置き換える。
+func (d *document) replace(start, end int, lines ...string) {
+	d.lines = slices.Concat(d.lines[:start], lines, d.lines[end:])
+}
+
+// valueScanner は複数行にまたがる値 (配列、インラインテーブル、複数行文字列) の終端を追跡する。
+type valueScanner struct {
+	depth   int
+	multi   string
+	comment int
+}
+
+// feed は1行分を読み、値が次の行に続くかを返す。
+// 値の後ろにコメントがあれば、その開始位置を comment に記録する (無ければ -1)。
+func (v *valueScanner) feed(line string) bool {
+	v.comment = -1
+	for i := 0; i < len(line); i++ {
+		if v.multi != "" {
+			j := strings.Index(line[i:], v.multi)
+			if j < 0 {
+				return true
+			}
+			i += j + len(v.multi) - 1
+			v.multi = ""
+			continue
+		}
+		switch c := line[i]; c {
+		case '#':
+			v.comment = i
+			return v.depth > 0
+		case '"', '\'':
+			delim := string([]byte{c, c, c})
+			if strings.HasPrefix(line[i:], delim) {
+				v.multi = delim
+				i += 2
+				continue
+			}
+			for i++; i < len(line) && line[i] != c; i++ {
+				if c == '"' && line[i] == '\\' {
+					i++
+				}
+			}
+		case '[', '{':
+			v.depth++
+		case ']', '}':
+			v.depth--
+		}
+	}
+	return v.depth > 0 || v.multi != ""
+}
+
+// splitDottedKey は `a."b.c"` のようなドット区切りキーを要素に分解する。
+func splitDottedKey(s string) []string {
+	var parts []string
+	for s = strings.TrimSpace(s); s != ""; {
+		var part string
+		switch s[0] {
+		case '"', '\'':
+			end := strings.IndexByte(s[1:], s[0])
+			if end < 0 {
+				return append(parts, s)
+			}
+			part = s[1 : end+1]
+			if s[0] == '"' {
+				part = strings.ReplaceAll(strings.ReplaceAll(part, `\"`, `"`), `\\`, `\`)
+			}
+			s = s[end+2:]
+		default:
+			end := strings.IndexByte(s, '.')
+			if end < 0 {
+				end = len(s)
+			}
+			part = strings.TrimSpace(s[:end])
+			s = s[end:]
+		}
+		parts = append(parts, part)
+		s = strings.TrimPrefix(strings.TrimSpace(s), ".")
+		s = strings.TrimSpace(s)
+	}
+	return parts
+}
+
+func formatKey(key string) string {
+	if bareKeyRe.MatchString(key) {
+		return key
+	}
+	return formatString(key)
+}
+
+func formatTableName(path []string) string {
+	parts := make([]string, len(path))
+	for i, p := range path {
+		parts[i] = formatKey(p)
+	}
+	return strings.Join(parts, ".")
+}
+
+// formatStringList は items を TOML の文字列の配列として書き出す。
+func formatStringList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = formatString(item)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// formatString は s を TOML の basic string として書き出す。
+func formatString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+				continue
+			}
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
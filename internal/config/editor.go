@@ -0,0 +1,428 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// Editor は設定ファイルをコメントや include 構成を保ったまま書き換える。
+// 変更はプロファイルが定義されているファイルに書き込み、保存前に Loader.Load と同じ検証を行う。
+type Editor struct {
+	loader *Loader
+	// staged が nil でなければ変更をファイルに書かず、パスごとの内容として保持する
+	staged map[string][]byte
+	order  []string
+}
+
+func NewEditor(path string) *Editor {
+	return &Editor{loader: NewLoader(path)}
+}
+
+// SetLenient は検証を寛容モードにする。Loader.SetLenient を参照。
+func (e *Editor) SetLenient(lenient bool) {
+	e.loader.SetLenient(lenient)
+}
+
+// Staged は変更をファイルに書かずメモリ上に保持する Editor を返す。
+// 後続の操作は保持した内容を前提に行われ、Changes で内容を取り出せる。
+func (e *Editor) Staged() *Editor {
+	return &Editor{loader: e.loader, staged: make(map[string][]byte)}
+}
+
+// FileChange は書き込み予定のファイルと内容。
+type FileChange struct {
+	Path    string
+	Content []byte
+}
+
+// Changes は Staged な Editor が保持している変更を、最初に変更された順に返す。
+func (e *Editor) Changes() []FileChange {
+	changes := make([]FileChange, len(e.order))
+	for i, path := range e.order {
+		changes[i] = FileChange{Path: path, Content: e.staged[path]}
+	}
+	return changes
+}
+
+// ProfileSource はプロファイル名と、それが定義されているファイル。
+type ProfileSource struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+}
+
+// KeyValue は設定ファイルに書かれたキーと値。
+type KeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// List はプロファイルと定義元ファイルを名前順に返す。
+func (e *Editor) List() ([]ProfileSource, error) {
+	entries, _, err := e.loader.collect()
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]ProfileSource, 0, len(entries))
+	for name, src := range entries {
+		sources = append(sources, ProfileSource{Name: name, File: src.file})
+	}
+	sort.Slice(sources, func(i, j int) bool { return sources[i].Name < sources[j].Name })
+	return sources, nil
+}
+
+// Resolve は解決済みのプロファイルを定義元付きで返す。Loader.Resolve を参照。
+func (e *Editor) Resolve() ([]ResolvedProfile, error) {
+	return e.loader.Resolve()
+}
+
+// Values はプロファイルに書かれているキーと値を返す。値は展開前の記述のまま。
+func (e *Editor) Values(name string) ([]KeyValue, error) {
+	table, err := e.rawProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	order := func(key string) int {
+		for i, k := range profileKeys {
+			if k.Name == key {
+				return i
+			}
+		}
+		return len(profileKeys)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if oi, oj := order(keys[i]), order(keys[j]); oi != oj {
+			return oi < oj
+		}
+		return keys[i] < keys[j]
+	})
+
+	values := make([]KeyValue, 0, len(keys))
+	for _, key := range keys {
+		values = append(values, KeyValue{Key: key, Value: formatValue(table[key])})
+	}
+	return values, nil
+}
+
+// Get はプロファイルに書かれているキーの値を返す。
+func (e *Editor) Get(name, key string) (string, error) {
+	table, err := e.rawProfile(name)
+	if err != nil {
+		return "", err
+	}
+	v, ok := table[key]
+	if !ok {
+		if _, known := lookupKey(key); !known {
+			return "", unknownKeyError(key)
+		}
+		return "", fmt.Errorf("profile %q: key %q is not set", name, key)
+	}
+	return formatValue(v), nil
+}
+
+// Set はプロファイルのキーに文字列値を設定する。
+func (e *Editor) Set(name, key, value string) error {
+	spec, ok := lookupKey(key)
+	if !ok {
+		return unknownKeyError(key)
+	}
+	literal, err := spec.literal(value)
+	if err != nil {
+		return err
+	}
+	file, doc, err := e.profileDocument(name)
+	if err != nil {
+		return err
+	}
+	if err := doc.setKey([]string{name}, key, literal); err != nil {
+		return err
+	}
+	return e.save(file, doc.bytes())
+}
+
+// Unset はプロファイルからキーを削除する。
+func (e *Editor) Unset(name, key string) error {
+	file, doc, err := e.profileDocument(name)
+	if err != nil {
+		return err
+	}
+	if !doc.removeKey([]string{name}, key) {
+		return fmt.Errorf("profile %q: key %q is not set", name, key)
+	}
+	return e.save(file, doc.bytes())
+}
+
+// Add はプロファイルを追加する。file が空ならメインの設定ファイルに追記する。
+// file を指定する場合はメインファイルか include されているファイルでなければならない。
+func (e *Editor) Add(name, file string, values []KeyValue) error {
+	if name == "" {
+		return domain.ErrEmptyName
+	}
+	entries, files, err := e.loader.collect()
+	if err != nil {
+		return err
+	}
+	if src, exists := entries[name]; exists {
+		return fmt.Errorf("profile %q (defined in %s): %w", name, src.file, domain.ErrDuplicateProfile)
+	}
+
+	target := e.loader.Path()
+	if file != "" {
+		target, err = matchFile(file, files)
+		if err != nil {
+			return err
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	literals := make([]string, 0, len(values))
+	for _, kv := range values {
+		spec, ok := lookupKey(kv.Key)
+		if !ok {
+			return unknownKeyError(kv.Key)
+		}
+		literal, err := spec.literal(kv.Value)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, kv.Key)
+		literals = append(literals, literal)
+	}
+
+	data, err := e.loader.readFile(target)
+	if err != nil {
+		return err
+	}
+	doc := parseDocument(data)
+	doc.appendTable([]string{name}, keys, literals)
+	return e.save(target, doc.bytes())
+}
+
+// Remove はプロファイルを、そのサブテーブルと直前のコメントごと削除する。
+func (e *Editor) Remove(name string) error {
+	file, doc, err := e.profileDocument(name)
+	if err != nil {
+		return err
+	}
+	doc.removeTable([]string{name})
+	return e.save(file, doc.bytes())
+}
+
+// Edit はプロファイルの定義元ファイル (name が空ならメインファイル) の一時コピーを open で編集させ、
+// 検証に通れば元のファイルに書き戻す。変更が無ければ false を返す。
+// 検証に失敗した場合は編集内容を一時ファイルに残し、そのパスをエラーに含める。
+func (e *Editor) Edit(name string, open func(path string) error) (bool, error) {
+	file := e.loader.Path()
+	if name != "" {
+		var err error
+		if file, err = e.locate(name); err != nil {
+			return false, err
+		}
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+
+	tmp, err := os.CreateTemp("", "gh-mrepo-*"+filepath.Ext(file))
+	if err != nil {
+		return false, err
+	}
+	tmpPath := tmp.Name()
+	// 保存に失敗したときだけ、編集内容を残すために一時ファイルを消さない
+	keep := false
+	defer func() {
+		if !keep {
+			_ = os.Remove(tmpPath)
+		}
+	}()
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := open(tmpPath); err != nil {
+		return false, err
+	}
+	edited, err := os.ReadFile(tmpPath)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(edited, data) {
+		return false, nil
+	}
+	if err := e.save(file, edited); err != nil {
+		keep = true
+		return false, fmt.Errorf("%w\nyour changes are kept in %s", err, tmpPath)
+	}
+	return true, nil
+}
+
+// locate は name のプロファイルが定義されているファイルを返す。
+func (e *Editor) locate(name string) (string, error) {
+	entries, _, err := e.loader.collect()
+	if err != nil {
+		return "", err
+	}
+	src, ok := entries[name]
+	if !ok {
+		return "", fmt.Errorf("profile %q not found", name)
+	}
+	return src.file, nil
+}
+
+// profileDocument は name のプロファイルが [name] テーブルとして書かれているファイルを読み込む。
+func (e *Editor) profileDocument(name string) (string, *document, error) {
+	file, err := e.locate(name)
+	if err != nil {
+		return "", nil, err
+	}
+	data, err := e.loader.readFile(file)
+	if err != nil {
+		return "", nil, err
+	}
+	doc := parseDocument(data)
+	if !doc.hasTable([]string{name}) {
+		return "", nil, fmt.Errorf("profile %q is not written as a [%s] table in %s; use `gh mrepo config edit` instead",
+			name, formatKey(name), file)
+	}
+	return file, doc, nil
+}
+
+func (e *Editor) rawProfile(name string) (map[string]any, error) {
+	file, err := e.locate(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := e.loader.readFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if _, err := toml.Decode(string(data), &raw); err != nil {
+		return nil, fmt.Errorf("failed to load config %q: %w", file, err)
+	}
+	table, _ := raw[name].(map[string]any)
+	return table, nil
+}
+
+// save は data を path の内容とみなして設定全体を検証し、問題なければ書き込む。
+func (e *Editor) save(path string, data []byte) error {
+	loader := e.loader.withOverride(path, data)
+	if _, err := loader.Load(); err != nil {
+		return fmt.Errorf("invalid config, %s was not changed: %w", path, err)
+	}
+	if e.staged != nil {
+		if _, ok := e.staged[path]; !ok {
+			e.order = append(e.order, path)
+		}
+		e.staged[path] = data
+		e.loader = loader
+		return nil
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// matchFile は file が files のいずれかと同じファイルであればそのパスを返す。
+func matchFile(file string, files []string) (string, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range files {
+		if fa, err := filepath.Abs(f); err == nil && fa == abs {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("%s is neither the config file nor one of its includes", file)
+}
+
+func unknownKeyError(key string) error {
+	if hint := didYouMean(key, keyNames(profileKeys)); hint != "" {
+		return fmt.Errorf("%w %q (%s)", domain.ErrUnknownKey, key, hint)
+	}
+	return fmt.Errorf("%w %q (available: %s)", domain.ErrUnknownKey, key, strings.Join(keyNames(profileKeys), ", "))
+}
+
+// formatValue は TOML から読んだ値を表示用の文字列にする。文字列はそのまま、それ以外は TOML 表記で返す。
+func formatValue(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return formatLiteral(v)
+}
+
+func formatLiteral(v any) string {
+	switch v := v.(type) {
+	case string:
+		return formatString(v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		items := make([]string, len(keys))
+		for i, k := range keys {
+			items[i] = formatKey(k) + " = " + formatLiteral(v[k])
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// writeFileAtomic は一時ファイルに書いてから rename で置き換える。
+// シンボリックリンクはリンク先を書き換え、既存ファイルのパーミッションを保つ。
+// 新規ファイルは mode で作成する。
+func writeFileAtomic(path string, data []byte, mode os.FileMode) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Chmod(mode)
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,524 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func writeConfig(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readConfig(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestEditor_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		key     string
+		value   string
+		want    string
+	}{
+		{
+			name: "既存のキーを置き換えてコメントを保持する",
+			content: `# gh-mrepo configuration
+
+# 仕事用
+[work]
+gh_config_dir = "~/.config/gh-work" # work account
+root = "~/repos/work"
+
+[personal]
+gh_config_dir = "~/.config/gh"
+`,
+			key:   "gh_config_dir",
+			value: "~/.config/gh-corp",
+			want: `# gh-mrepo configuration
+
+# 仕事用
+[work]
+gh_config_dir = "~/.config/gh-corp" # work account
+root = "~/repos/work"
+
+[personal]
+gh_config_dir = "~/.config/gh"
+`,
+		},
+		{
+			name: "無いキーはテーブルの最後のキーの後に追加する",
+			content: `[work]
+gh_config_dir = "~/.config/gh-work"
+# root = "~/repos"
+
+[personal]
+gh_config_dir = "~/.config/gh"
+`,
+			key:   "host",
+			value: "ghe.example.com",
+			want: `[work]
+gh_config_dir = "~/.config/gh-work"
+host = "ghe.example.com"
+# root = "~/repos"
+
+[personal]
+gh_config_dir = "~/.config/gh"
+`,
+		},
+		{
+			name: "値はTOML文字列としてエスケープする",
+			content: `[work]
+gh_config_dir = "~/.config/gh-work"
+`,
+			key:   "git_config_name",
+			value: `Taro "T" Yamada`,
+			want: `[work]
+gh_config_dir = "~/.config/gh-work"
+git_config_name = "Taro \"T\" Yamada"
+`,
+		},
+		{
+			name: "複数行の値やサブテーブルを壊さない",
+			content: `include = [
+  "a.toml", # [not a table]
+]
+
+[work]
+gh_config_dir = "~/.config/gh-work"
+
+[work.extra]
+note = "x"
+`,
+			key:   "root",
+			value: "~/w",
+			want: `include = [
+  "a.toml", # [not a table]
+]
+
+[work]
+gh_config_dir = "~/.config/gh-work"
+root = "~/w"
+
+[work.extra]
+note = "x"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "config.toml")
+			writeConfig(t, path, tt.content)
+			if strings.Contains(tt.content, "a.toml") {
+				writeConfig(t, filepath.Join(dir, "a.toml"), "")
+			}
+
+			// [work.extra] は未知のテーブルなので寛容モードで検証する
+			e := config.NewEditor(path)
+			e.SetLenient(true)
+			if err := e.Set("work", tt.key, tt.value); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := readConfig(t, path); got != tt.want {
+				t.Errorf("config =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditor_SetWritesToIncludedFile(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config.toml")
+	incPath := filepath.Join(dir, "work.toml")
+	mainContent := `# main
+include = ["work.toml"]
+
+[personal]
+gh_config_dir = "~/.config/gh"
+`
+	writeConfig(t, mainPath, mainContent)
+	writeConfig(t, incPath, `# work profiles
+[work]
+gh_config_dir = "~/.config/gh-work"
+`)
+
+	if err := config.NewEditor(mainPath).Set("work", "root", "~/repos/work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := readConfig(t, mainPath); got != mainContent {
+		t.Errorf("main config changed:\n%s", got)
+	}
+	want := `# work profiles
+[work]
+gh_config_dir = "~/.config/gh-work"
+root = "~/repos/work"
+`
+	if got := readConfig(t, incPath); got != want {
+		t.Errorf("included config =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestEditor_SetErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	content := `[work]
+gh_config_dir = "~/.config/gh-work"
+`
+	writeConfig(t, path, content)
+	e := config.NewEditor(path)
+
+	tests := []struct {
+		name    string
+		profile string
+		key     string
+		value   string
+		wantErr string
+	}{
+		{name: "未知のキー", profile: "work", key: "rooot", value: "x", wantErr: `unknown key "rooot"`},
+		{name: "存在しないプロファイル", profile: "nope", key: "root", value: "x", wantErr: `profile "nope" not found`},
+		{name: "検証に失敗する値", profile: "work", key: "gh_config_dir", value: "", wantErr: "gh_config_dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := e.Set(tt.profile, tt.key, tt.value)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("err = %v, want containing %q", err, tt.wantErr)
+			}
+			if got := readConfig(t, path); got != content {
+				t.Errorf("config changed on error:\n%s", got)
+			}
+		})
+	}
+}
+
+func TestEditor_Add(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config.toml")
+	incPath := filepath.Join(dir, "extra.toml")
+	writeConfig(t, mainPath, `include = ["extra.toml"]
+
+[work]
+gh_config_dir = "~/.config/gh-work"
+`)
+	writeConfig(t, incPath, "# extra\n")
+	e := config.NewEditor(mainPath)
+
+	err := e.Add("personal", "", []config.KeyValue{
+		{Key: "gh_config_dir", Value: "~/.config/gh"},
+		{Key: "root", Value: "~/repos"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `include = ["extra.toml"]
+
+[work]
+gh_config_dir = "~/.config/gh-work"
+
+[personal]
+gh_config_dir = "~/.config/gh"
+root = "~/repos"
+`
+	if got := readConfig(t, mainPath); got != want {
+		t.Errorf("config =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := e.Add("oss", incPath, []config.KeyValue{{Key: "gh_config_dir", Value: "~/.config/gh-oss"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wantInc := `# extra
+
+[oss]
+gh_config_dir = "~/.config/gh-oss"
+`
+	if got := readConfig(t, incPath); got != wantInc {
+		t.Errorf("included config =\n%s\nwant\n%s", got, wantInc)
+	}
+}
+
+func TestEditor_AddErrors(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	content := `[work]
+gh_config_dir = "~/.config/gh-work"
+`
+	writeConfig(t, path, content)
+	e := config.NewEditor(path)
+
+	if err := e.Add("work", "", []config.KeyValue{{Key: "gh_config_dir", Value: "x"}}); !errors.Is(err, domain.ErrDuplicateProfile) {
+		t.Errorf("err = %v, want ErrDuplicateProfile", err)
+	}
+	if err := e.Add("new", "", []config.KeyValue{{Key: "root", Value: "~/r"}}); !errors.Is(err, domain.ErrEmptyGHConfigDir) {
+		t.Errorf("err = %v, want ErrEmptyGHConfigDir", err)
+	}
+	other := filepath.Join(dir, "other.toml")
+	if err := e.Add("new", other, []config.KeyValue{{Key: "gh_config_dir", Value: "x"}}); err == nil {
+		t.Error("expected error for a file that is not included")
+	}
+	if got := readConfig(t, path); got != content {
+		t.Errorf("config changed on error:\n%s", got)
+	}
+}
+
+func TestEditor_Remove(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	writeConfig(t, path, `# gh-mrepo configuration
+
+# 仕事用
+[work]
+gh_config_dir = "~/.config/gh-work"
+
+[work.extra]
+note = "x"
+
+# 個人用
+[personal]
+gh_config_dir = "~/.config/gh"
+`)
+	e := config.NewEditor(path)
+	e.SetLenient(true)
+
+	if err := e.Remove("work"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# gh-mrepo configuration
+
+# 個人用
+[personal]
+gh_config_dir = "~/.config/gh"
+`
+	if got := readConfig(t, path); got != want {
+		t.Errorf("config =\n%s\nwant\n%s", got, want)
+	}
+
+	if err := e.Remove("personal"); !errors.Is(err, domain.ErrNoProfiles) {
+		t.Errorf("removing the last profile: err = %v, want ErrNoProfiles", err)
+	}
+	if err := e.Remove("nope"); err == nil {
+		t.Error("expected error for unknown profile")
+	}
+}
+
+func TestEditor_GetAndValues(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	writeConfig(t, path, `[work]
+root = "~/repos/work"
+gh_config_dir = "~/.config/gh-work"
+`)
+	e := config.NewEditor(path)
+
+	got, err := e.Get("work", "root")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "~/repos/work" {
+		t.Errorf("Get = %q, want raw value", got)
+	}
+	if _, err := e.Get("work", "host"); err == nil {
+		t.Error("expected error for unset key")
+	}
+
+	values, err := e.Values("work")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []config.KeyValue{
+		{Key: "gh_config_dir", Value: "~/.config/gh-work"},
+		{Key: "root", Value: "~/repos/work"},
+	}
+	if len(values) != len(want) {
+		t.Fatalf("Values = %v, want %v", values, want)
+	}
+	for i := range want {
+		if values[i] != want[i] {
+			t.Errorf("Values[%d] = %v, want %v", i, values[i], want[i])
+		}
+	}
+}
+
+func TestEditor_List(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config.toml")
+	incPath := filepath.Join(dir, "work.toml")
+	writeConfig(t, mainPath, `include = ["work.toml"]
+[personal]
+gh_config_dir = "~/.config/gh"
+`)
+	writeConfig(t, incPath, `[work]
+gh_config_dir = "~/.config/gh-work"
+`)
+
+	got, err := config.NewEditor(mainPath).List()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []config.ProfileSource{
+		{Name: "personal", File: mainPath},
+		{Name: "work", File: incPath},
+	}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("List = %v, want %v", got, want)
+	}
+}
+
+func TestEditor_Edit(t *testing.T) {
+	content := `# keep me
+[work]
+gh_config_dir = "~/.config/gh-work"
+`
+	tests := []struct {
+		name        string
+		edited      string
+		wantChanged bool
+		wantErr     bool
+		wantContent string
+	}{
+		{
+			name:        "検証に通れば書き戻す",
+			edited:      content + "root = \"~/w\"\n",
+			wantChanged: true,
+			wantContent: content + "root = \"~/w\"\n",
+		},
+		{
+			name:        "変更なし",
+			edited:      content,
+			wantContent: content,
+		},
+		{
+			name:        "検証に失敗したら元のファイルを変更しない",
+			edited:      "[work\n",
+			wantErr:     true,
+			wantContent: content,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			writeConfig(t, path, content)
+
+			var tmpPath string
+			changed, err := config.NewEditor(path).Edit("", func(p string) error {
+				tmpPath = p
+				return os.WriteFile(p, []byte(tt.edited), 0o600)
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if got := readConfig(t, path); got != tt.wantContent {
+				t.Errorf("config =\n%s\nwant\n%s", got, tt.wantContent)
+			}
+			if tt.wantErr {
+				if !strings.Contains(err.Error(), tmpPath) {
+					t.Errorf("error should mention the kept temp file: %v", err)
+				}
+				os.Remove(tmpPath)
+			} else if _, err := os.Stat(tmpPath); !os.IsNotExist(err) {
+				t.Errorf("temp file %s should be removed", tmpPath)
+			}
+		})
+	}
+}
+
+func TestEditor_WriteFollowsSymlink(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "dotfiles.toml")
+	link := filepath.Join(dir, "config.toml")
+	writeConfig(t, real, `[work]
+gh_config_dir = "~/.config/gh-work"
+`)
+	if err := os.Symlink(real, link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := config.NewEditor(link).Set("work", "root", "~/w"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fi, err := os.Lstat(link); err != nil || fi.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("symlink was replaced: %v", err)
+	}
+	if got := readConfig(t, real); !strings.Contains(got, `root = "~/w"`) {
+		t.Errorf("link target not updated:\n%s", got)
+	}
+}
+
+func TestEditor_SetBool(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `[base]
+gh_config_dir = "~/.config/gh"
+
+[work]
+extends = "base"
+`)
+	e := config.NewEditor(path)
+
+	if err := e.Set("base", "abstract", "yes"); err == nil {
+		t.Error("expected error for a non-boolean value")
+	}
+	if err := e.Set("base", "abstract", "true"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readConfig(t, path); !strings.Contains(got, "abstract = true\n") {
+		t.Errorf("config =\n%s\nwant abstract = true", got)
+	}
+}
+
+func TestEditor_SetInt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, "[work]\ngh_config_dir = \"~/.config/gh\"\n")
+	e := config.NewEditor(path)
+
+	if err := e.Set("work", "scan_depth", "deep"); err == nil {
+		t.Error("expected error for a non-integer value")
+	}
+	if err := e.Set("work", "scan_depth", "3"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readConfig(t, path); !strings.Contains(got, "scan_depth = 3\n") {
+		t.Errorf("config =\n%s\nwant scan_depth = 3", got)
+	}
+	if got, err := e.Get("work", "scan_depth"); err != nil || got != "3" {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+}
+
+func TestEditor_SetTags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, "[work]\ngh_config_dir = \"~/.config/gh\"\n")
+	e := config.NewEditor(path)
+
+	if err := e.Set("work", "tags", "work, corp,"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := readConfig(t, path); !strings.Contains(got, `tags = ["work", "corp"]`+"\n") {
+		t.Errorf("config =\n%s\nwant tags = [\"work\", \"corp\"]", got)
+	}
+	if got, err := e.Get("work", "tags"); err != nil || got != `["work", "corp"]` {
+		t.Errorf("Get = %q, %v", got, err)
+	}
+	// テーブルはコマンドラインからは設定できない
+	if err := e.Set("work", "env", "GH_PAGER=cat"); err == nil || !strings.Contains(err.Error(), "config edit") {
+		t.Errorf("Set env: err = %v, want hint to use config edit", err)
+	}
+}
@@ -4,10 +4,54 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
+// HostsFile は gh の hosts.yml を表す。キーはホスト名。
+type HostsFile map[string]HostEntry
+
+// HostEntry は hosts.yml の1ホスト分の設定。
+// gh 2.40 以降は複数アカウントを users に保持し、アクティブなアカウントを user に持つ。
+type HostEntry struct {
+	User        string              `yaml:"user"`
+	GitProtocol string              `yaml:"git_protocol"`
+	Users       map[string]HostUser `yaml:"users"`
+}
+
+// HostUser は hosts.yml の users 配下の1アカウント分の設定。
+type HostUser struct {
+	OAuthToken string `yaml:"oauth_token,omitempty"`
+}
+
+// UserNames はログイン済みアカウント名をソートして返す。
+// 旧形式 (users なし) の場合はアクティブユーザーのみを返す。
+func (e HostEntry) UserNames() []string {
+	names := make([]string, 0, len(e.Users)+1)
+	for name := range e.Users {
+		names = append(names, name)
+	}
+	if e.User != "" {
+		if _, ok := e.Users[e.User]; !ok {
+			names = append(names, e.User)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasUser は name がこのホストにログイン済みかを返す。
+func (e HostEntry) HasUser(name string) bool {
+	if name == e.User {
+		return true
+	}
+	_, ok := e.Users[name]
+	return ok
+}
+
 // HostResolver は app.UserResolver を満たすアダプタ。
 type HostResolver struct{}
 
@@ -15,30 +59,65 @@ func NewHostResolver() *HostResolver {
 	return &HostResolver{}
 }
 
-func (h *HostResolver) ResolveGitHubUser(ghConfigDir string) (string, error) {
-	return ResolveGitHubUser(ghConfigDir)
+func (h *HostResolver) ResolveGitHubUser(profile domain.Profile) (string, error) {
+	return ResolveGitHubUser(profile.GHConfigDir, profile.HostName(), profile.User)
 }
 
-// ResolveGitHubUser は ghConfigDir/hosts.yml を読んで github.com の user を返す。
-func ResolveGitHubUser(ghConfigDir string) (string, error) {
-	data, err := os.ReadFile(filepath.Join(ghConfigDir, "hosts.yml"))
+// ResolveGitProtocol は profile の hosts.yml のホストの git_protocol を返す。
+// ホストのエントリがなければ空文字列を返す。
+func (h *HostResolver) ResolveGitProtocol(profile domain.Profile) (string, error) {
+	hosts, err := ReadHosts(profile.GHConfigDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to read hosts.yml: %w", err)
+		return "", err
 	}
+	return hosts[profile.HostName()].GitProtocol, nil
+}
+
+// Hosts は ghConfigDir/hosts.yml 全体を読み込む。
+func (h *HostResolver) Hosts(ghConfigDir string) (HostsFile, error) {
+	return ReadHosts(ghConfigDir)
+}
 
-	var hosts map[string]struct {
-		User string `yaml:"user"`
+// ReadHosts は ghConfigDir/hosts.yml を読み込む。
+func ReadHosts(ghConfigDir string) (HostsFile, error) {
+	data, err := os.ReadFile(filepath.Join(ghConfigDir, "hosts.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts.yml: %w", err)
 	}
+
+	var hosts HostsFile
 	if err := yaml.Unmarshal(data, &hosts); err != nil {
-		return "", fmt.Errorf("failed to parse hosts.yml: %w", err)
+		return nil, fmt.Errorf("failed to parse hosts.yml: %w", err)
 	}
+	return hosts, nil
+}
 
-	entry, ok := hosts["github.com"]
+// ResolveGitHubUser は ghConfigDir/hosts.yml を読んで host のユーザー名を返す。
+// host が空の場合は github.com を対象とする。
+// user を指定した場合はそのアカウントがログイン済みであることを確認して返し、
+// 空の場合はアクティブユーザーを返す。
+func ResolveGitHubUser(ghConfigDir, host, user string) (string, error) {
+	if host == "" {
+		host = domain.DefaultHost
+	}
+
+	hosts, err := ReadHosts(ghConfigDir)
+	if err != nil {
+		return "", err
+	}
+
+	entry, ok := hosts[host]
 	if !ok {
-		return "", fmt.Errorf("github.com entry not found in hosts.yml")
+		return "", fmt.Errorf("%s entry not found in hosts.yml", host)
+	}
+	if user != "" {
+		if !entry.HasUser(user) {
+			return "", fmt.Errorf("user %q is not logged in to %s in hosts.yml", user, host)
+		}
+		return user, nil
 	}
 	if entry.User == "" {
-		return "", fmt.Errorf("user is empty for github.com in hosts.yml")
+		return "", fmt.Errorf("user is empty for %s in hosts.yml", host)
 	}
 	return entry.User, nil
 }
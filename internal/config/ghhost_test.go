@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/sarrrrry/gh-mrepo/internal/config"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
 func TestResolveGitHubUser(t *testing.T) {
@@ -13,7 +14,7 @@ func TestResolveGitHubUser(t *testing.T) {
 		dir := t.TempDir()
 		writeHostsYml(t, dir, "github.com:\n    user: sarrrrry\n")
 
-		got, err := config.ResolveGitHubUser(dir)
+		got, err := config.ResolveGitHubUser(dir, "", "")
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -25,7 +26,7 @@ func TestResolveGitHubUser(t *testing.T) {
 	t.Run("hosts.ymlが存在しない", func(t *testing.T) {
 		dir := t.TempDir()
 
-		_, err := config.ResolveGitHubUser(dir)
+		_, err := config.ResolveGitHubUser(dir, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -35,7 +36,7 @@ func TestResolveGitHubUser(t *testing.T) {
 		dir := t.TempDir()
 		writeHostsYml(t, dir, "gitlab.com:\n    user: someone\n")
 
-		_, err := config.ResolveGitHubUser(dir)
+		_, err := config.ResolveGitHubUser(dir, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
@@ -45,13 +46,139 @@ func TestResolveGitHubUser(t *testing.T) {
 		dir := t.TempDir()
 		writeHostsYml(t, dir, "github.com:\n    user: \"\"\n")
 
-		_, err := config.ResolveGitHubUser(dir)
+		_, err := config.ResolveGitHubUser(dir, "", "")
 		if err == nil {
 			t.Fatal("expected error, got nil")
 		}
 	})
 }
 
+func TestResolveGitHubUser_EnterpriseHost(t *testing.T) {
+	dir := t.TempDir()
+	writeHostsYml(t, dir, "github.com:\n    user: octocat\nghe.example.com:\n    user: octocat-corp\n")
+
+	got, err := config.ResolveGitHubUser(dir, "ghe.example.com", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "octocat-corp" {
+		t.Errorf("got %q, want %q", got, "octocat-corp")
+	}
+
+	_, err = config.ResolveGitHubUser(dir, "other.example.com", "")
+	if err == nil {
+		t.Fatal("expected error for unknown host, got nil")
+	}
+}
+
+const multiAccountHostsYml = `github.com:
+    git_protocol: ssh
+    users:
+        octocat:
+        octocat-work:
+            oauth_token: gho_xxx
+    user: octocat
+`
+
+func TestResolveGitHubUser_MultiAccount(t *testing.T) {
+	dir := t.TempDir()
+	writeHostsYml(t, dir, multiAccountHostsYml)
+
+	t.Run("user未指定はアクティブユーザー", func(t *testing.T) {
+		got, err := config.ResolveGitHubUser(dir, "", "")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "octocat" {
+			t.Errorf("got %q, want %q", got, "octocat")
+		}
+	})
+
+	t.Run("非アクティブなアカウントを指定", func(t *testing.T) {
+		got, err := config.ResolveGitHubUser(dir, "", "octocat-work")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got != "octocat-work" {
+			t.Errorf("got %q, want %q", got, "octocat-work")
+		}
+	})
+
+	t.Run("ログインしていないアカウント", func(t *testing.T) {
+		_, err := config.ResolveGitHubUser(dir, "", "someone")
+		if err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	})
+}
+
+func TestHostResolver_ResolveGitProtocol(t *testing.T) {
+	dir := t.TempDir()
+	writeHostsYml(t, dir, multiAccountHostsYml+"ghe.example.com:\n    user: octocat-corp\n")
+	resolver := config.NewHostResolver()
+
+	tests := []struct {
+		name string
+		host string
+		want string
+	}{
+		{name: "git_protocol あり", host: "", want: "ssh"},
+		{name: "git_protocol なし", host: "ghe.example.com", want: ""},
+		{name: "ホストのエントリなし", host: "other.example.com", want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolver.ResolveGitProtocol(domain.Profile{Name: "work", GHConfigDir: dir, Host: tt.host})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := resolver.ResolveGitProtocol(domain.Profile{Name: "work", GHConfigDir: t.TempDir()}); err == nil {
+		t.Fatal("expected error for missing hosts.yml, got nil")
+	}
+}
+
+func TestReadHosts(t *testing.T) {
+	dir := t.TempDir()
+	writeHostsYml(t, dir, multiAccountHostsYml)
+
+	hosts, err := config.ReadHosts(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entry, ok := hosts["github.com"]
+	if !ok {
+		t.Fatal("github.com entry not found")
+	}
+	if entry.User != "octocat" {
+		t.Errorf("User = %q, want %q", entry.User, "octocat")
+	}
+	if entry.GitProtocol != "ssh" {
+		t.Errorf("GitProtocol = %q, want %q", entry.GitProtocol, "ssh")
+	}
+	names := entry.UserNames()
+	if len(names) != 2 || names[0] != "octocat" || names[1] != "octocat-work" {
+		t.Errorf("UserNames() = %v, want [octocat octocat-work]", names)
+	}
+	if entry.Users["octocat-work"].OAuthToken != "gho_xxx" {
+		t.Errorf("octocat-work token = %q", entry.Users["octocat-work"].OAuthToken)
+	}
+}
+
+func TestHostEntry_UserNames_LegacyFormat(t *testing.T) {
+	entry := config.HostEntry{User: "octocat"}
+	names := entry.UserNames()
+	if len(names) != 1 || names[0] != "octocat" {
+		t.Errorf("UserNames() = %v, want [octocat]", names)
+	}
+}
+
 func writeHostsYml(t *testing.T, dir, content string) {
 	t.Helper()
 	if err := os.WriteFile(filepath.Join(dir, "hosts.yml"), []byte(content), 0o644); err != nil {
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// conditionalInclude は [[include_if]] の1エントリ。指定した条件をすべて満たすときだけ path を読み込む。
+type conditionalInclude struct {
+	Path string `toml:"path"`
+	// Hostname はホスト名に対する glob パターン (大文字小文字を区別しない)
+	Hostname string `toml:"hostname"`
+	// Env は "VAR" (空でない値が設定されている) または "VAR=value" (値が一致する)
+	Env string `toml:"env"`
+}
+
+// matches は条件を満たすかを返す。
+func (c conditionalInclude) matches(hostname func() (string, error)) (bool, error) {
+	if c.Path == "" {
+		return false, errors.New("include_if: path is required")
+	}
+	if c.Hostname == "" && c.Env == "" {
+		return false, fmt.Errorf("include_if %q: set hostname or env", c.Path)
+	}
+	if c.Hostname != "" {
+		host, err := hostname()
+		if err != nil {
+			return false, err
+		}
+		ok, err := path.Match(strings.ToLower(c.Hostname), strings.ToLower(host))
+		if err != nil {
+			return false, fmt.Errorf("include_if %q: invalid hostname pattern %q: %w", c.Path, c.Hostname, err)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	if c.Env != "" {
+		name, want, hasValue := strings.Cut(c.Env, "=")
+		got := os.Getenv(name)
+		if hasValue && got != want || !hasValue && got == "" {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// resolveInclude は include の1エントリを読み込むファイルのパスに解決する。
+// glob パターンは一致したファイルを名前順に返し、一致が無くてもエラーにしない。
+func (l *Loader) resolveInclude(include string) ([]string, error) {
+	resolved, err := l.resolveIncludePath(include)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.ContainsAny(resolved, "*?[") {
+		return []string{resolved}, nil
+	}
+	matches, err := filepath.Glob(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("include %q in %q: %w", include, l.path, err)
+	}
+	sort.Strings(matches)
+	return matches, nil
+}
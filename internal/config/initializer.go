@@ -22,16 +22,33 @@ func NewInitializer() *Initializer {
 	return &Initializer{}
 }
 
+// Init はテンプレートの設定ファイルを書き出す。
 func (i *Initializer) Init(configPath string) error {
+	return i.Write(configPath, []byte(templateConfig))
+}
+
+// Check は configPath にまだ設定ファイルが無いことを確認する。
+func (i *Initializer) Check(configPath string) error {
 	if _, err := os.Stat(configPath); err == nil {
 		return fmt.Errorf("%w: %s", domain.ErrConfigAlreadyExists, configPath)
 	}
+	return nil
+}
+
+// Write は data を検証し、新しい設定ファイルとして書き出す。
+func (i *Initializer) Write(configPath string, data []byte) error {
+	if err := i.Check(configPath); err != nil {
+		return err
+	}
+	if _, err := NewLoader(configPath).withOverride(configPath, data).Load(); err != nil {
+		return err
+	}
 
 	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
 		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
-	if err := os.WriteFile(configPath, []byte(templateConfig), 0o644); err != nil {
+	if err := os.WriteFile(configPath, data, 0o644); err != nil {
 		return fmt.Errorf("failed to write config: %w", err)
 	}
 
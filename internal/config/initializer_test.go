@@ -64,3 +64,15 @@ func TestInit_ErrorWhenConfigAlreadyExists(t *testing.T) {
 		t.Errorf("err = %v, want %v", err, domain.ErrConfigAlreadyExists)
 	}
 }
+
+func TestWrite_RejectsInvalidConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+
+	err := config.NewInitializer().Write(configPath, []byte("[work]\nroot = \"~/repos\"\n"))
+	if !errors.Is(err, domain.ErrEmptyGHConfigDir) {
+		t.Fatalf("err = %v, want ErrEmptyGHConfigDir", err)
+	}
+	if _, err := os.Stat(configPath); !os.IsNotExist(err) {
+		t.Error("invalid config should not be written")
+	}
+}
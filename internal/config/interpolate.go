@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// interpolate は s 中の ${VAR} と ${VAR:-default} を環境変数で置き換える。
+// ${VAR} の VAR が未定義ならエラー、${VAR:-default} は VAR が未定義か空なら default (これも展開する) を使う。
+// $${ と書くと ${ そのものになる。
+func interpolate(s string) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if strings.HasPrefix(s[i:], "$${") {
+			b.WriteString("${")
+			i += 2
+			continue
+		}
+		if !strings.HasPrefix(s[i:], "${") {
+			b.WriteByte(s[i])
+			continue
+		}
+
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q", s)
+		}
+		expr := s[i+2 : end]
+		name, def, hasDefault := strings.Cut(expr, ":-")
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s)
+		}
+		value, ok := os.LookupEnv(name)
+		switch {
+		case hasDefault && value == "":
+			expanded, err := interpolate(def)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+		case !ok:
+			return "", fmt.Errorf("${%s}: %w", name, domain.ErrUndefinedVariable)
+		default:
+			b.WriteString(value)
+		}
+		i = end
+	}
+	return b.String(), nil
+}
+
+// closingBrace は start 以降で ${ の対応する } の位置を返す。default 内の入れ子も考慮する。
+func closingBrace(s string, start int) int {
+	depth := 1
+	for i := start; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// interpolateEntry は profileEntry の文字列 (配列やテーブルの中の文字列を含む) をすべて展開する。
+// エラーにはキー名を含める。
+func interpolateEntry(entry profileEntry) (profileEntry, error) {
+	v := reflect.ValueOf(&entry).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		expanded, err := interpolateValue(v.Field(i))
+		if err != nil {
+			return profileEntry{}, fmt.Errorf("%s: %w", v.Type().Field(i).Tag.Get("toml"), err)
+		}
+		v.Field(i).Set(expanded)
+	}
+	return entry, nil
+}
+
+// interpolateValue は v の中の文字列を展開した値を返す。配列とテーブルは複製する。
+func interpolateValue(v reflect.Value) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.String:
+		s, err := interpolate(v.String())
+		return reflect.ValueOf(s).Convert(v.Type()), err
+	case reflect.Slice:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := interpolateValue(v.Index(i))
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(item)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			item, err := interpolateValue(it.Value())
+			if err != nil {
+				return v, fmt.Errorf("%s: %w", it.Key(), err)
+			}
+			out.SetMapIndex(it.Key(), item)
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyKind はキーの値の型。
+type keyKind int
+
+const (
+	kindString keyKind = iota
+	kindBool
+	// kindStringList は文字列の配列
+	kindStringList
+	// kindStringMap は値が文字列のテーブル
+	kindStringMap
+	// kindListMap は値が文字列の配列のテーブル
+	kindListMap
+	kindInt
+)
+
+// tomlType は toml.MetaData.Type が返す型名を返す。
+func (k keyKind) tomlType() string {
+	switch k {
+	case kindBool:
+		return "Bool"
+	case kindInt:
+		return "Integer"
+	case kindStringList:
+		return "Array"
+	case kindStringMap, kindListMap:
+		return "Hash"
+	default:
+		return "String"
+	}
+}
+
+// valueTomlType はテーブルの値の toml.MetaData.Type の型名を返す。テーブルでない種類なら空文字列。
+func (k keyKind) valueTomlType() string {
+	switch k {
+	case kindStringMap:
+		return "String"
+	case kindListMap:
+		return "Array"
+	default:
+		return ""
+	}
+}
+
+func (k keyKind) String() string {
+	return typeName(k.tomlType())
+}
+
+// keySpec はプロファイルに書けるキーの定義。
+type keySpec struct {
+	Name string
+	Kind keyKind
+	// Path ならチルダを展開したファイルパスとして扱う
+	Path bool
+	// Own なら extends で親から引き継がない
+	Own bool
+	// Description は JSON Schema にも載せる説明
+	Description string
+}
+
+// profileKeys はプロファイルに書けるキーの一覧。profileEntry の toml タグと対応する。
+var profileKeys = []keySpec{
+	{Name: "gh_config_dir", Path: true, Description: "Path to the gh config directory (GH_CONFIG_DIR) for this account."},
+	{Name: "root", Path: true, Description: "Root directory for cloning repositories."},
+	{Name: "git_config_name", Description: "user.name set via git config --local on switch."},
+	{Name: "git_config_email", Description: "user.email set via git config --local on switch."},
+	{Name: "ssh_identity", Path: true, Description: "Path to the SSH private key used for git over SSH."},
+	{Name: "host", Description: "GitHub hostname (default: github.com)."},
+	{Name: "user", Description: "Account to use when gh_config_dir holds several logged-in accounts."},
+	{Name: "layout", Description: "Path of a clone under root, built from {host}, {owner} and {repo} (default: {owner}/{repo}). Use {host}/{owner}/{repo} to share a ghq root."},
+	{Name: "scan_depth", Kind: kindInt, Description: "How many directory levels under root to search for repositories (default or 0: the depth of layout)."},
+	{Name: "exclude", Kind: kindStringList, Description: "Glob patterns for directories under root that lls skips, e.g. [\"archive\", \"*/tmp-*\"]."},
+	{Name: "env", Kind: kindStringMap, Description: "Environment variables set when running gh or exec, e.g. { GH_PAGER = \"cat\" }."},
+	{Name: "defaults", Kind: kindListMap, Description: "Default arguments per gh subcommand, e.g. { \"repo list\" = [\"--limit\", \"200\"] }. Arguments you pass win."},
+	{Name: "tags", Kind: kindStringList, Description: "Tags for selecting profiles with --tag and --exclude-tag."},
+	{Name: "aliases", Kind: kindStringList, Own: true, Description: "Other names for the profile, accepted by --user, GH_MREPO_PROFILE and markers."},
+	{Name: "extends", Own: true, Description: "Profile to inherit unset keys from."},
+	{Name: "abstract", Kind: kindBool, Own: true, Description: "Only used as a base for extends; never selected."},
+}
+
+// includeIfSpecs は [[include_if]] に書けるキーの一覧。conditionalInclude の toml タグと対応する。
+var includeIfSpecs = []keySpec{
+	{Name: "path", Path: true, Description: "File to load when the conditions match."},
+	{Name: "hostname", Description: "Glob pattern matched against the hostname (case-insensitive)."},
+	{Name: "env", Description: `"VAR" to require a non-empty variable, or "VAR=value" to require that value.`},
+}
+
+// keyNames はキー名を定義順に返す。
+func keyNames(specs []keySpec) []string {
+	names := make([]string, len(specs))
+	for i, k := range specs {
+		names[i] = k.Name
+	}
+	return names
+}
+
+// lookupKey は name のキー定義を返す。
+func lookupKey(name string) (keySpec, bool) {
+	for _, k := range profileKeys {
+		if k.Name == name {
+			return k, true
+		}
+	}
+	return keySpec{}, false
+}
+
+// literal はコマンドラインで渡された値をキーの型に合わせた TOML 表記にする。
+func (k keySpec) literal(value string) (string, error) {
+	switch k.Kind {
+	case kindBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: expected true or false, got %q", k.Name, value)
+		}
+		return strconv.FormatBool(b), nil
+	case kindInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: expected an integer, got %q", k.Name, value)
+		}
+		return strconv.Itoa(n), nil
+	case kindStringList:
+		// カンマ区切りで受け取る。空文字列は空の配列
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return formatStringList(items), nil
+	case kindStringMap, kindListMap:
+		return "", fmt.Errorf("%s is a table; use `gh mrepo config edit` to change it", k.Name)
+	default:
+		return formatString(value), nil
+	}
+}
@@ -1,9 +1,12 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"sort"
 	"strings"
 
@@ -18,24 +21,122 @@ type profileEntry struct {
 	GitConfigName  string `toml:"git_config_name"`
 	GitConfigEmail string `toml:"git_config_email"`
 	SSHIdentity    string `toml:"ssh_identity"`
+	Host           string `toml:"host"`
+	User           string `toml:"user"`
+	// Layout は root 配下のリポジトリのパスのテンプレート
+	Layout string `toml:"layout"`
+	// ScanDepth は root 配下でリポジトリを探す最大の階層数
+	ScanDepth int `toml:"scan_depth"`
+	// Exclude は root 配下でたどらないディレクトリの glob パターン
+	Exclude []string `toml:"exclude"`
+	// Tags は --tag で絞り込むためのタグ
+	Tags []string `toml:"tags"`
+	// Aliases はプロファイル名の代わりに使える別名
+	Aliases []string `toml:"aliases"`
+	// Env は gh やコマンドの実行時に追加する環境変数
+	Env map[string]string `toml:"env"`
+	// Defaults はサブコマンドごとの既定の引数
+	Defaults map[string][]string `toml:"defaults"`
+	// Extends は未設定の項目を引き継ぐ親プロファイル名
+	Extends string `toml:"extends"`
+	// Abstract なプロファイルは継承元専用で、選択対象にならない
+	Abstract bool `toml:"abstract"`
+}
+
+// inherit は e の未設定の項目を parent の値で埋めたものを返す。
+// env などのテーブルはキーごとに埋める。
+// extends、abstract、aliases のようにプロファイル固有のキー (keySpec.Own) は引き継がない。
+func (e profileEntry) inherit(parent profileEntry) profileEntry {
+	child := reflect.ValueOf(&e).Elem()
+	pv := reflect.ValueOf(parent)
+	for i := 0; i < child.NumField(); i++ {
+		if spec, _ := lookupKey(child.Type().Field(i).Tag.Get("toml")); spec.Own {
+			continue
+		}
+		f, pf := child.Field(i), pv.Field(i)
+		switch {
+		case f.Kind() == reflect.Map && !f.IsNil() && !pf.IsNil():
+			// 親のテーブルを書き換えないよう複製してから埋める
+			merged := reflect.MakeMapWithSize(f.Type(), f.Len()+pf.Len())
+			for _, m := range []reflect.Value{pf, f} {
+				for it := m.MapRange(); it.Next(); {
+					merged.SetMapIndex(it.Key(), it.Value())
+				}
+			}
+			f.Set(merged)
+		case f.IsZero():
+			f.Set(pf)
+		}
+	}
+	return e
 }
 
 type Loader struct {
-	path string
+	path      string
+	overrides map[string][]byte
+	hostname  func() (string, error)
+	// lenient なら未知のキーとプロファイル以外のトップレベルの値を無視する
+	lenient bool
 }
 
 func NewLoader(path string) *Loader {
-	return &Loader{path: path}
+	return &Loader{path: path, hostname: os.Hostname}
+}
+
+// SetLenient は未知のキーなどを無視する寛容モードを設定する。
+// 新しいバージョン向けに書かれた設定を古いバージョンで読むための逃げ道。
+func (l *Loader) SetLenient(lenient bool) {
+	l.lenient = lenient
+}
+
+// Path はメイン設定ファイルのパスを返す。
+func (l *Loader) Path() string {
+	return l.path
+}
+
+// withOverride は path の内容を data に差し替えて読む Loader を返す。保存前の検証に使う。
+func (l *Loader) withOverride(path string, data []byte) *Loader {
+	overrides := make(map[string][]byte, len(l.overrides)+1)
+	for k, v := range l.overrides {
+		overrides[k] = v
+	}
+	overrides[filepath.Clean(path)] = data
+	return &Loader{path: l.path, overrides: overrides, hostname: l.hostname, lenient: l.lenient}
+}
+
+func (l *Loader) readFile(path string) ([]byte, error) {
+	if data, ok := l.overrides[filepath.Clean(path)]; ok {
+		return data, nil
+	}
+	return os.ReadFile(path)
 }
 
 // loadFile は1つのTOMLファイルから include パスとプロファイルを取り出す
 func (l *Loader) loadFile(path string) ([]string, map[string]profileEntry, error) {
+	data, err := l.readFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && path == l.path {
+			return nil, nil, fmt.Errorf("config file %s not found (run `gh mrepo init` to create it): %w", path, err)
+		}
+		return nil, nil, fmt.Errorf("failed to load config %q: %w", path, err)
+	}
 	var raw map[string]toml.Primitive
-	md, err := toml.DecodeFile(path, &raw)
+	md, err := toml.Decode(string(data), &raw)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to load config %q: %w", path, err)
 	}
 
+	var issues []*ValidationError
+	// include されたファイルの include は読まないので、黙って無視せずに報告する
+	if path != l.path {
+		for _, key := range []string{"include", "include_if"} {
+			if _, ok := raw[key]; ok {
+				issues = append(issues, &ValidationError{Key: []string{key}, Err: domain.ErrNestedInclude,
+					Detail: "nested includes are not supported; move it to " + l.path})
+			}
+		}
+	}
+
 	var includes []string
 	if prim, ok := raw["include"]; ok {
 		if err := md.PrimitiveDecode(prim, &includes); err != nil {
@@ -43,66 +144,215 @@ func (l *Loader) loadFile(path string) ([]string, map[string]profileEntry, error
 		}
 		delete(raw, "include")
 	}
+	var conditional []conditionalInclude
+	if prim, ok := raw["include_if"]; ok {
+		if err := md.PrimitiveDecode(prim, &conditional); err != nil {
+			return nil, nil, fmt.Errorf("invalid include_if value in %q: %w", path, err)
+		}
+		delete(raw, "include_if")
+	}
+
+	names := make([]string, 0, len(raw))
+	for name := range raw {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
 	profiles := make(map[string]profileEntry, len(raw))
-	for name, prim := range raw {
+	for _, name := range names {
+		if t := md.Type(name); t != "Hash" {
+			if !l.lenient {
+				issues = append(issues, &ValidationError{Key: []string{name}, Err: domain.ErrNotATable,
+					Detail: fmt.Sprintf("got %s; only include and include_if may be set outside a profile", typeName(t))})
+			}
+			continue
+		}
+		typeIssues := len(issues)
+		for _, spec := range profileKeys {
+			if t := md.Type(name, spec.Name); t != "" && t != spec.Kind.tomlType() {
+				issues = append(issues, &ValidationError{Key: []string{name, spec.Name}, Err: domain.ErrInvalidType,
+					Detail: fmt.Sprintf("expected %s, got %s", spec.Kind, typeName(t))})
+			}
+		}
+		issues = append(issues, valueTypeIssues(md, name)...)
+		if len(issues) > typeIssues {
+			continue
+		}
+
 		var entry profileEntry
-		if err := md.PrimitiveDecode(prim, &entry); err != nil {
+		if err := md.PrimitiveDecode(raw[name], &entry); err != nil {
 			return nil, nil, fmt.Errorf("profile %q in %q: %w", name, path, err)
 		}
 		profiles[name] = entry
 	}
 
+	if !l.lenient {
+		issues = append(issues, unknownKeys(md)...)
+	}
+	if len(issues) > 0 {
+		return nil, nil, joinValidationErrors(path, parseDocument(data), issues)
+	}
+
+	// 条件の判定はキーの検証の後に行い、path の綴り間違いを未知のキーとして報告する
+	for _, c := range conditional {
+		ok, err := c.matches(l.hostname)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		if ok {
+			includes = append(includes, c.Path)
+		}
+	}
+
 	return includes, profiles, nil
 }
 
-func (l *Loader) Load() ([]domain.Profile, error) {
+// valueTypeIssues は profile の env や defaults のようなテーブルの値の型を調べる。
+// デコードのエラーには位置が含まれないので、キーごとに調べて行番号を付けられるようにする。
+func valueTypeIssues(md toml.MetaData, profile string) []*ValidationError {
+	var issues []*ValidationError
+	for _, key := range md.Keys() {
+		if len(key) != 3 || key[0] != profile || md.Type(key[:2]...) != "Hash" {
+			continue
+		}
+		spec, ok := lookupKey(key[1])
+		want := spec.Kind.valueTomlType()
+		if !ok || want == "" {
+			continue
+		}
+		if t := md.Type(key...); t != want {
+			issues = append(issues, &ValidationError{Key: slices.Clone(key), Err: domain.ErrInvalidType,
+				Detail: fmt.Sprintf("expected %s, got %s", typeName(want), typeName(t))})
+		}
+	}
+	return issues
+}
+
+// unknownKeys はデコードされなかったキーを未知のキーとして報告する。
+// 未知のテーブルの中身は、そのテーブル自体の報告にまとめる。
+// 型の違いでデコードしなかったプロファイルの既知のキーは報告しない。
+func unknownKeys(md toml.MetaData) []*ValidationError {
+	var issues []*ValidationError
+	var reported [][]string
+	for _, key := range md.Undecoded() {
+		if len(key) < 2 || slices.ContainsFunc(reported, func(r []string) bool {
+			return len(r) <= len(key) && slices.Equal(r, key[:len(r)])
+		}) {
+			continue
+		}
+		candidates := keyNames(profileKeys)
+		if key[0] == "include_if" {
+			candidates = keyNames(includeIfSpecs)
+		} else if md.Type(key[0]) != "Hash" {
+			continue
+		}
+		if slices.Contains(candidates, key[1]) {
+			continue
+		}
+		issues = append(issues, &ValidationError{Key: key, Err: domain.ErrUnknownKey, Detail: didYouMean(key[len(key)-1], candidates)})
+		reported = append(reported, key)
+	}
+	return issues
+}
+
+// sourcedEntry はプロファイル定義と、それが書かれているファイルの組。
+type sourcedEntry struct {
+	entry profileEntry
+	file  string
+}
+
+// collect はメインファイルと include ファイルからプロファイル定義を集める。
+// 読み込んだファイルの一覧 (メインファイルが先頭) も返す。
+func (l *Loader) collect() (map[string]sourcedEntry, []string, error) {
 	includes, mainProfiles, err := l.loadFile(l.path)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
+	files := []string{l.path}
 
-	// includeファイルのプロファイルを収集
-	merged := make(map[string]profileEntry)
+	// includeファイルのプロファイルを収集 (同じファイルは1度だけ読む)
+	var resolvedIncludes []string
 	for _, inc := range includes {
-		resolved, err := l.resolveIncludePath(inc)
+		paths, err := l.resolveInclude(inc)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		for _, p := range paths {
+			if !slices.Contains(resolvedIncludes, p) {
+				resolvedIncludes = append(resolvedIncludes, p)
+			}
 		}
+	}
+
+	merged := make(map[string]sourcedEntry)
+	for _, resolved := range resolvedIncludes {
 		_, fileProfiles, err := l.loadFile(resolved)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		files = append(files, resolved)
 		for name, entry := range fileProfiles {
-			if _, exists := merged[name]; exists {
-				return nil, fmt.Errorf("profile %q: %w", name, domain.ErrDuplicateProfile)
+			if prev, exists := merged[name]; exists {
+				return nil, nil, fmt.Errorf("profile %q in %q and %q: %w", name, prev.file, resolved, domain.ErrDuplicateProfile)
 			}
-			merged[name] = entry
+			merged[name] = sourcedEntry{entry: entry, file: resolved}
 		}
 	}
 
 	// メインファイルのプロファイルをマージ (重複チェック)
 	for name, entry := range mainProfiles {
-		if _, exists := merged[name]; exists {
-			return nil, fmt.Errorf("profile %q: %w", name, domain.ErrDuplicateProfile)
+		if prev, exists := merged[name]; exists {
+			return nil, nil, fmt.Errorf("profile %q in %q and %q: %w", name, prev.file, l.path, domain.ErrDuplicateProfile)
 		}
-		merged[name] = entry
+		merged[name] = sourcedEntry{entry: entry, file: l.path}
 	}
 
-	if len(merged) == 0 {
-		return nil, domain.ErrNoProfiles
+	return merged, files, nil
+}
+
+// Files はメインファイルと include されたファイルのパスを返す。
+func (l *Loader) Files() ([]string, error) {
+	_, files, err := l.collect()
+	return files, err
+}
+
+func (l *Loader) Load() ([]domain.Profile, error) {
+	merged, _, err := l.collect()
+	if err != nil {
+		return nil, err
 	}
 
-	// ソートして安定した順序を保証
-	names := make([]string, 0, len(merged))
-	for name := range merged {
-		names = append(names, name)
+	for name, src := range merged {
+		entry, err := interpolateEntry(src.entry)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q in %q: %w", name, src.file, err)
+		}
+		merged[name] = sourcedEntry{entry: entry, file: src.file}
+	}
+
+	resolved, err := resolveExtends(merged)
+	if err != nil {
+		return nil, err
+	}
+
+	// ソートして安定した順序を保証 (abstract なプロファイルは除く)
+	names := make([]string, 0, len(resolved))
+	for name, entry := range resolved {
+		if !entry.Abstract {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("%s: %w", l.path, domain.ErrNoProfiles)
 	}
 	sort.Strings(names)
+	if err := checkAliases(names, resolved, merged); err != nil {
+		return nil, err
+	}
 
-	profiles := make([]domain.Profile, 0, len(merged))
+	profiles := make([]domain.Profile, 0, len(names))
 	for _, name := range names {
-		entry := merged[name]
+		entry := resolved[name]
 		ghConfigDir, err := expandTilde(entry.GHConfigDir)
 		if err != nil {
 			return nil, err
@@ -114,10 +364,29 @@ func (l *Loader) Load() ([]domain.Profile, error) {
 
 		p, err := domain.NewProfile(name, ghConfigDir, root)
 		if err != nil {
-			return nil, fmt.Errorf("profile %q: %w", name, err)
+			return nil, fmt.Errorf("profile %q in %q: %w", name, merged[name].file, err)
 		}
 		p.GitConfigName = entry.GitConfigName
 		p.GitConfigEmail = entry.GitConfigEmail
+		p.Host = entry.Host
+		p.User = entry.User
+		p.Tags = entry.Tags
+		p.Aliases = entry.Aliases
+		p.Env = entry.Env
+		p.Defaults = entry.Defaults
+		if entry.Layout != "" {
+			if p.Layout, err = domain.ParseLayout(entry.Layout); err != nil {
+				return nil, fmt.Errorf("profile %q in %q: %w", name, merged[name].file, err)
+			}
+		}
+		if entry.ScanDepth < 0 {
+			return nil, fmt.Errorf("profile %q in %q: scan_depth must not be negative (0 means the depth of layout), got %d", name, merged[name].file, entry.ScanDepth)
+		}
+		p.ScanDepth = entry.ScanDepth
+		if err := domain.ValidateExclude(entry.Exclude); err != nil {
+			return nil, fmt.Errorf("profile %q in %q: %w", name, merged[name].file, err)
+		}
+		p.Exclude = entry.Exclude
 
 		sshIdentity, err := expandTilde(entry.SSHIdentity)
 		if err != nil {
@@ -131,9 +400,71 @@ func (l *Loader) Load() ([]domain.Profile, error) {
 	return profiles, nil
 }
 
-// resolveIncludePath はチルダ展開と相対パスのconfig基準解決を行う
+// checkAliases は別名がほかのプロファイルの名前や別名と重ならないことを確かめる。
+func checkAliases(names []string, resolved map[string]profileEntry, merged map[string]sourcedEntry) error {
+	owners := make(map[string]string, len(names))
+	for _, name := range names {
+		owners[name] = name
+	}
+	for _, name := range names {
+		for _, alias := range resolved[name].Aliases {
+			if owner, ok := owners[alias]; ok && owner != name {
+				return fmt.Errorf("profile %q in %q: alias %q: %w %q", name, merged[name].file, alias, domain.ErrAliasConflict, owner)
+			}
+			owners[alias] = name
+		}
+	}
+	return nil
+}
+
+// resolveExtends は extends をたどり、親の値を引き継いだ定義を返す。
+// 親はどのファイルに定義されていてもよい。循環している場合はエラーを返す。
+func resolveExtends(entries map[string]sourcedEntry) (map[string]profileEntry, error) {
+	resolved := make(map[string]profileEntry, len(entries))
+	var resolve func(name string, chain []string) (profileEntry, error)
+	resolve = func(name string, chain []string) (profileEntry, error) {
+		if entry, ok := resolved[name]; ok {
+			return entry, nil
+		}
+		if i := slices.Index(chain, name); i >= 0 {
+			cycle := append(slices.Clone(chain[i:]), name)
+			return profileEntry{}, fmt.Errorf("profile %q in %q: %w: %s", chain[0], entries[chain[0]].file, domain.ErrExtendsCycle, strings.Join(cycle, " -> "))
+		}
+		entry := entries[name].entry
+		if entry.Extends != "" {
+			if _, ok := entries[entry.Extends]; !ok {
+				return profileEntry{}, fmt.Errorf("profile %q in %q: %w %q", name, entries[name].file, domain.ErrUnknownParent, entry.Extends)
+			}
+			parent, err := resolve(entry.Extends, append(chain, name))
+			if err != nil {
+				return profileEntry{}, err
+			}
+			entry = entry.inherit(parent)
+		}
+		resolved[name] = entry
+		return entry, nil
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := resolve(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// resolveIncludePath は環境変数とチルダの展開、相対パスのconfig基準解決を行う
 func (l *Loader) resolveIncludePath(path string) (string, error) {
-	expanded, err := expandTilde(path)
+	interpolated, err := interpolate(path)
+	if err != nil {
+		return "", fmt.Errorf("include %q in %q: %w", path, l.path, err)
+	}
+	expanded, err := expandTilde(interpolated)
 	if err != nil {
 		return "", err
 	}
@@ -2,8 +2,11 @@ package config_test
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/sarrrrry/gh-mrepo/internal/config"
@@ -248,9 +251,12 @@ func TestLoad_NoProfiles(t *testing.T) {
 
 	loader := config.NewLoader(tomlPath)
 	_, err := loader.Load()
-	if err != domain.ErrNoProfiles {
+	if !errors.Is(err, domain.ErrNoProfiles) {
 		t.Errorf("err = %v, want %v", err, domain.ErrNoProfiles)
 	}
+	if !strings.Contains(err.Error(), tomlPath) {
+		t.Errorf("err = %v, want the config path in the message", err)
+	}
 }
 
 // --- include機能のテスト ---
@@ -658,3 +664,800 @@ gh_config_dir = "/home/user/.config/gh-personal"
 		t.Errorf("personal.GHConfigDir = %q", personal.GHConfigDir)
 	}
 }
+
+func TestLoad_HostAndUser(t *testing.T) {
+	dir := t.TempDir()
+	tomlPath := filepath.Join(dir, "config.toml")
+	content := `
+[work]
+gh_config_dir = "/home/user/.config/gh-work"
+host = "ghe.example.com"
+user = "octocat-corp"
+
+[personal]
+gh_config_dir = "/home/user/.config/gh-personal"
+`
+	if err := os.WriteFile(tomlPath, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := config.NewLoader(tomlPath)
+	profiles, err := loader.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	m := make(map[string]domain.Profile)
+	for _, p := range profiles {
+		m[p.Name] = p
+	}
+
+	if got := m["work"].HostName(); got != "ghe.example.com" {
+		t.Errorf("work.HostName() = %q, want %q", got, "ghe.example.com")
+	}
+	if got := m["work"].User; got != "octocat-corp" {
+		t.Errorf("work.User = %q, want %q", got, "octocat-corp")
+	}
+	if got := m["personal"].HostName(); got != domain.DefaultHost {
+		t.Errorf("personal.HostName() = %q, want %q", got, domain.DefaultHost)
+	}
+}
+
+func TestLoad_Tags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `[base]
+abstract = true
+tags = ["work"]
+
+[corp]
+extends = "base"
+gh_config_dir = "/gh-corp"
+
+[oss]
+gh_config_dir = "/gh-oss"
+tags = ["oss", "personal"]
+`)
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	got := map[string][]string{}
+	for _, p := range profiles {
+		got[p.Name] = p.Tags
+	}
+	want := map[string][]string{"corp": {"work"}, "oss": {"oss", "personal"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tags = %v, want %v", got, want)
+	}
+
+	writeConfig(t, path, "[work]\ngh_config_dir = \"/gh\"\ntags = \"work\"\n")
+	if _, err := config.NewLoader(path).Load(); !errors.Is(err, domain.ErrInvalidType) {
+		t.Errorf("err = %v, want %v", err, domain.ErrInvalidType)
+	}
+}
+
+func TestLoad_Aliases(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `[work]
+gh_config_dir = "/gh-work"
+aliases = ["w", "corp"]
+
+[work-ghe]
+extends = "work"
+host = "ghe.example.com"
+`)
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 2 || !reflect.DeepEqual(profiles[0].Aliases, []string{"w", "corp"}) {
+		t.Errorf("profiles = %+v, want work with aliases", profiles)
+	}
+	// 別名は extends で引き継がない
+	if profiles[1].Aliases != nil {
+		t.Errorf("work-ghe aliases = %v, want none", profiles[1].Aliases)
+	}
+}
+
+func TestLoad_AliasConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "ほかのプロファイル名",
+			content: "[work]\ngh_config_dir = \"/gh\"\naliases = [\"personal\"]\n\n[personal]\ngh_config_dir = \"/gh\"\n",
+		},
+		{
+			name:    "ほかのプロファイルの別名",
+			content: "[work]\ngh_config_dir = \"/gh\"\naliases = [\"x\"]\n\n[personal]\ngh_config_dir = \"/gh\"\naliases = [\"x\"]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			writeConfig(t, path, tt.content)
+
+			if _, err := config.NewLoader(path).Load(); !errors.Is(err, domain.ErrAliasConflict) {
+				t.Errorf("err = %v, want %v", err, domain.ErrAliasConflict)
+			}
+		})
+	}
+}
+
+func TestLoad_EnvAndDefaults(t *testing.T) {
+	t.Setenv("GH_MREPO_TEST_PROXY", "http://proxy:8080")
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `[base]
+abstract = true
+env = { GH_PAGER = "cat", HTTPS_PROXY = "${GH_MREPO_TEST_PROXY}" }
+
+[base.defaults]
+"repo list" = ["--limit", "200"]
+
+[work]
+extends = "base"
+gh_config_dir = "/gh"
+
+[work.env]
+GH_PAGER = "less"
+
+[work.defaults]
+"repo clone" = ["--", "--filter=blob:none"]
+`)
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 1 {
+		t.Fatalf("profiles = %+v, want only work", profiles)
+	}
+	// テーブルはキーごとに親から引き継ぐ
+	wantEnv := map[string]string{"GH_PAGER": "less", "HTTPS_PROXY": "http://proxy:8080"}
+	if !reflect.DeepEqual(profiles[0].Env, wantEnv) {
+		t.Errorf("Env = %v, want %v", profiles[0].Env, wantEnv)
+	}
+	wantDefaults := map[string][]string{"repo list": {"--limit", "200"}, "repo clone": {"--", "--filter=blob:none"}}
+	if !reflect.DeepEqual(profiles[0].Defaults, wantDefaults) {
+		t.Errorf("Defaults = %v, want %v", profiles[0].Defaults, wantDefaults)
+	}
+
+	writeConfig(t, path, "[work]\ngh_config_dir = \"/gh\"\nenv = \"GH_PAGER=cat\"\n")
+	if _, err := config.NewLoader(path).Load(); !errors.Is(err, domain.ErrInvalidType) {
+		t.Errorf("err = %v, want %v", err, domain.ErrInvalidType)
+	}
+}
+
+func TestLoad_Layout(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `[base]
+abstract = true
+root = "/ghq"
+layout = "{host}/{owner}/{repo}"
+
+[work]
+extends = "base"
+gh_config_dir = "/gh"
+`)
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := profiles[0].Layout.String(); got != "{host}/{owner}/{repo}" {
+		t.Errorf("Layout = %q, want the inherited layout", got)
+	}
+
+	writeConfig(t, path, "[work]\ngh_config_dir = \"/gh\"\nlayout = \"{repo}\"\n")
+	_, err = config.NewLoader(path).Load()
+	if err == nil || !strings.Contains(err.Error(), `profile "work"`) || !strings.Contains(err.Error(), "must contain {owner}") {
+		t.Errorf("err = %v, want an invalid layout error for work", err)
+	}
+}
+
+func TestLoad_ScanSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `[work]
+gh_config_dir = "/gh"
+scan_depth = 4
+exclude = ["archive", "*/tmp-*"]
+`)
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profiles[0].ScanDepth != 4 || !reflect.DeepEqual(profiles[0].Exclude, []string{"archive", "*/tmp-*"}) {
+		t.Errorf("ScanDepth = %d, Exclude = %v", profiles[0].ScanDepth, profiles[0].Exclude)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		wantMsg string
+	}{
+		{"負のscan_depth", "[work]\ngh_config_dir = \"/gh\"\nscan_depth = -1\n", "scan_depth must not be negative"},
+		{"文字列のscan_depth", "[work]\ngh_config_dir = \"/gh\"\nscan_depth = \"2\"\n", "expected integer, got string"},
+		{"不正なパターン", "[work]\ngh_config_dir = \"/gh\"\nexclude = [\"[x\"]\n", `exclude pattern "[x"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writeConfig(t, path, tt.content)
+			_, err := config.NewLoader(path).Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestLoad_Extends(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config.toml")
+	basePath := filepath.Join(dir, "base.toml")
+	writeConfig(t, basePath, `
+[base]
+abstract = true
+gh_config_dir = "/home/user/.config/gh-work"
+git_config_name = "Taro Yamada"
+ssh_identity = "/home/user/.ssh/id_work"
+host = "ghe.example.com"
+`)
+	writeConfig(t, mainPath, `
+include = ["base.toml"]
+
+[work-a]
+extends = "base"
+root = "/repos/a"
+git_config_email = "taro@a.example.com"
+
+[work-b]
+extends = "work-a"
+root = "/repos/b"
+host = "github.com"
+`)
+
+	profiles, err := config.NewLoader(mainPath).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []domain.Profile{
+		{
+			Name: "work-a", GHConfigDir: "/home/user/.config/gh-work", Root: "/repos/a",
+			GitConfigName: "Taro Yamada", GitConfigEmail: "taro@a.example.com",
+			SSHIdentity: "/home/user/.ssh/id_work", Host: "ghe.example.com",
+		},
+		{
+			Name: "work-b", GHConfigDir: "/home/user/.config/gh-work", Root: "/repos/b",
+			GitConfigName: "Taro Yamada", GitConfigEmail: "taro@a.example.com",
+			SSHIdentity: "/home/user/.ssh/id_work", Host: "github.com",
+		},
+	}
+	if len(profiles) != len(want) {
+		t.Fatalf("profiles = %+v, want %+v (abstract base must be excluded)", profiles, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(profiles[i], want[i]) {
+			t.Errorf("profiles[%d] = %+v, want %+v", i, profiles[i], want[i])
+		}
+	}
+}
+
+func TestLoad_ExtendsErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+		wantMsg string
+	}{
+		{
+			name: "循環",
+			content: `
+[a]
+extends = "b"
+gh_config_dir = "/gh"
+
+[b]
+extends = "c"
+
+[c]
+extends = "a"
+`,
+			wantErr: domain.ErrExtendsCycle,
+			wantMsg: "a -> b -> c -> a",
+		},
+		{
+			name: "自分自身",
+			content: `
+[a]
+extends = "a"
+gh_config_dir = "/gh"
+`,
+			wantErr: domain.ErrExtendsCycle,
+		},
+		{
+			name: "存在しない親",
+			content: `
+[a]
+extends = "nope"
+gh_config_dir = "/gh"
+`,
+			wantErr: domain.ErrUnknownParent,
+			wantMsg: `"nope"`,
+		},
+		{
+			name: "abstract のみ",
+			content: `
+[base]
+abstract = true
+`,
+			wantErr: domain.ErrNoProfiles,
+		},
+		{
+			name: "継承しても gh_config_dir が無い",
+			content: `
+[base]
+abstract = true
+root = "/repos"
+
+[a]
+extends = "base"
+`,
+			wantErr: domain.ErrEmptyGHConfigDir,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			writeConfig(t, path, tt.content)
+
+			_, err := config.NewLoader(path).Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantMsg != "" && !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestLoad_EnvInterpolation(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GH_MREPO_TEST_BASE", dir)
+	t.Setenv("GH_MREPO_TEST_USER", "octocat")
+	t.Setenv("GH_MREPO_TEST_EMPTY", "")
+	writeConfig(t, filepath.Join(dir, "work.toml"), `
+[work]
+gh_config_dir = "${GH_MREPO_TEST_BASE}/gh-work"
+user = "${GH_MREPO_TEST_USER}"
+`)
+	path := filepath.Join(dir, "config.toml")
+	writeConfig(t, path, `
+include = ["${GH_MREPO_TEST_BASE}/work.toml"]
+
+[personal]
+gh_config_dir = "${GH_MREPO_TEST_UNSET:-~/.config/gh}"
+root = "${GH_MREPO_TEST_EMPTY:-${GH_MREPO_TEST_BASE}/repos}"
+git_config_name = "price $${NOT_EXPANDED}"
+ssh_identity = "${GH_MREPO_TEST_BASE}/id_${GH_MREPO_TEST_USER}"
+`)
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	home, _ := os.UserHomeDir()
+	want := []domain.Profile{
+		{
+			Name: "personal", GHConfigDir: filepath.Join(home, ".config", "gh"), Root: dir + "/repos",
+			GitConfigName: "price ${NOT_EXPANDED}", SSHIdentity: dir + "/id_octocat",
+		},
+		{Name: "work", GHConfigDir: dir + "/gh-work", User: "octocat"},
+	}
+	if len(profiles) != len(want) {
+		t.Fatalf("profiles = %+v, want %+v", profiles, want)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(profiles[i], want[i]) {
+			t.Errorf("profiles[%d] = %+v, want %+v", i, profiles[i], want[i])
+		}
+	}
+}
+
+func TestLoad_EnvInterpolationErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr error
+		wantMsg []string
+	}{
+		{
+			name: "未定義の変数はプロファイルとキーを示す",
+			content: `
+[work]
+gh_config_dir = "/gh"
+root = "${GH_MREPO_TEST_UNDEFINED}/repos"
+`,
+			wantErr: domain.ErrUndefinedVariable,
+			wantMsg: []string{`profile "work"`, "root", "GH_MREPO_TEST_UNDEFINED"},
+		},
+		{
+			name: "include パスの未定義の変数",
+			content: `
+include = ["${GH_MREPO_TEST_UNDEFINED}/x.toml"]
+
+[work]
+gh_config_dir = "/gh"
+`,
+			wantErr: domain.ErrUndefinedVariable,
+			wantMsg: []string{"include", "GH_MREPO_TEST_UNDEFINED"},
+		},
+		{
+			name: "閉じていない ${",
+			content: `
+[work]
+gh_config_dir = "${GH_MREPO_TEST_UNDEFINED"
+`,
+			wantMsg: []string{`profile "work"`, "gh_config_dir", "unterminated"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			writeConfig(t, path, tt.content)
+
+			_, err := config.NewLoader(path).Load()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			for _, msg := range tt.wantMsg {
+				if !strings.Contains(err.Error(), msg) {
+					t.Errorf("err = %v, want containing %q", err, msg)
+				}
+			}
+		})
+	}
+}
+
+func TestLoad_IncludeGlob(t *testing.T) {
+	dir := t.TempDir()
+	profilesDir := filepath.Join(dir, "profiles.d")
+	if err := os.MkdirAll(profilesDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, filepath.Join(profilesDir, "20-work.toml"), "[work]\ngh_config_dir = \"/gh-work\"\n")
+	writeConfig(t, filepath.Join(profilesDir, "10-base.toml"), "[base]\nabstract = true\nroot = \"/repos\"\n")
+	writeConfig(t, filepath.Join(profilesDir, "README.md"), "not toml")
+	path := filepath.Join(dir, "config.toml")
+	writeConfig(t, path, `
+include = ["profiles.d/*.toml", "profiles.d/20-work.toml", "empty.d/*.toml"]
+
+[personal]
+extends = "base"
+gh_config_dir = "/gh"
+`)
+
+	loader := config.NewLoader(path)
+	profiles, err := loader.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(profiles) != 2 || profiles[0].Name != "personal" || profiles[0].Root != "/repos" || profiles[1].Name != "work" {
+		t.Errorf("profiles = %+v", profiles)
+	}
+
+	files, err := loader.Files()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{path, filepath.Join(profilesDir, "10-base.toml"), filepath.Join(profilesDir, "20-work.toml")}
+	if strings.Join(files, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Files = %v, want %v (sorted, each file once)", files, want)
+	}
+}
+
+func TestLoad_NestedInclude(t *testing.T) {
+	for _, nested := range []string{
+		`include = ["more.toml"]`,
+		"[[include_if]]\nenv = \"CI\"\npath = \"more.toml\"",
+	} {
+		dir := t.TempDir()
+		writeConfig(t, filepath.Join(dir, "more.toml"), "[oss]\ngh_config_dir = \"/gh-oss\"\n")
+		writeConfig(t, filepath.Join(dir, "work.toml"), nested+"\n\n[work]\ngh_config_dir = \"/gh-work\"\n")
+		path := filepath.Join(dir, "config.toml")
+		writeConfig(t, path, "include = [\"work.toml\"]\n")
+		t.Setenv("CI", "true")
+
+		_, err := config.NewLoader(path).Load()
+		var verr *config.ValidationError
+		if !errors.As(err, &verr) || !errors.Is(err, domain.ErrNestedInclude) {
+			t.Fatalf("err = %v, want ErrNestedInclude", err)
+		}
+		if verr.File != filepath.Join(dir, "work.toml") || verr.Line != 1 || !strings.Contains(err.Error(), path) {
+			t.Errorf("err = %v, want work.toml:1 pointing to %s", err, path)
+		}
+	}
+}
+
+func TestLoad_IncludeIf(t *testing.T) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		t.Skip("hostname unavailable")
+	}
+	t.Setenv("GH_MREPO_TEST_SITE", "tokyo")
+	t.Setenv("GH_MREPO_TEST_CI", "")
+
+	dir := t.TempDir()
+	for _, name := range []string{"laptop", "ci", "tokyo", "osaka"} {
+		writeConfig(t, filepath.Join(dir, name+".toml"), fmt.Sprintf("[%s]\ngh_config_dir = \"/gh-%s\"\n", name, name))
+	}
+	path := filepath.Join(dir, "config.toml")
+	writeConfig(t, path, fmt.Sprintf(`
+[[include_if]]
+hostname = %q
+path = "laptop.toml"
+
+[[include_if]]
+env = "GH_MREPO_TEST_CI"
+path = "ci.toml"
+
+[[include_if]]
+env = "GH_MREPO_TEST_SITE=tokyo"
+path = "tokyo.toml"
+
+[[include_if]]
+env = "GH_MREPO_TEST_SITE=osaka"
+path = "osaka.toml"
+
+[default]
+gh_config_dir = "/gh"
+`, strings.ToUpper(hostname[:1])+"*"))
+
+	profiles, err := config.NewLoader(path).Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	if got, want := strings.Join(names, ","), "default,laptop,tokyo"; got != want {
+		t.Errorf("profiles = %s, want %s", got, want)
+	}
+}
+
+func TestLoad_IncludeIfErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantMsg string
+	}{
+		{
+			name:    "条件が無い",
+			content: "[[include_if]]\npath = \"a.toml\"\n",
+			wantMsg: "set hostname or env",
+		},
+		{
+			name:    "path が無い",
+			content: "[[include_if]]\nenv = \"HOME\"\n",
+			wantMsg: "path is required",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			writeConfig(t, path, tt.content+"\n[default]\ngh_config_dir = \"/gh\"\n")
+
+			_, err := config.NewLoader(path).Load()
+			if err == nil || !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("err = %v, want containing %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestLoad_StrictValidation(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantErr  error
+		wantMsgs []string
+	}{
+		{
+			name: "未知のキーは行番号と候補を示す",
+			content: `[work]
+gh_config_dir = "/gh"
+gh_confg_dir = "/gh"
+`,
+			wantErr:  domain.ErrUnknownKey,
+			wantMsgs: []string{`config.toml:3: profile "work": unknown key "gh_confg_dir" (did you mean "gh_config_dir"?)`},
+		},
+		{
+			name: "候補が無ければ示さない",
+			content: `[work]
+gh_config_dir = "/gh"
+color = "red"
+`,
+			wantErr:  domain.ErrUnknownKey,
+			wantMsgs: []string{`config.toml:3: profile "work": unknown key "color"` + "\n"},
+		},
+		{
+			name: "未知のサブテーブルは1件にまとめる",
+			content: `[work]
+gh_config_dir = "/gh"
+
+[work.extra]
+note = "x"
+other = "y"
+`,
+			wantErr:  domain.ErrUnknownKey,
+			wantMsgs: []string{`config.toml:4: profile "work": unknown key "extra"` + "\n"},
+		},
+		{
+			name: "型の違い",
+			content: `[work]
+gh_config_dir = "/gh"
+abstract = "yes"
+root = 1
+`,
+			wantErr: domain.ErrInvalidType,
+			wantMsgs: []string{
+				`config.toml:3: profile "work": invalid type for key "abstract" (expected boolean, got string)`,
+				`config.toml:4: profile "work": invalid type for key "root" (expected string, got integer)`,
+			},
+		},
+		{
+			name: "env の値の型の違い",
+			content: `[work]
+gh_config_dir = "/gh"
+
+[work.env]
+GH_PAGER = "cat"
+GH_PROMPT_DISABLED = 1
+`,
+			wantErr:  domain.ErrInvalidType,
+			wantMsgs: []string{`config.toml:6: profile "work": invalid type for key "env.GH_PROMPT_DISABLED" (expected string, got integer)`},
+		},
+		{
+			name: "インラインテーブルの defaults の値の型の違い",
+			content: `[work]
+gh_config_dir = "/gh"
+defaults = { "repo list" = "--limit 200" }
+`,
+			wantErr:  domain.ErrInvalidType,
+			wantMsgs: []string{`config.toml:3: profile "work": invalid type for key "defaults.repo list" (expected array, got string)`},
+		},
+		{
+			name: "プロファイル以外のトップレベルの値",
+			content: `inclde = ["a.toml"]
+
+[work]
+gh_config_dir = "/gh"
+`,
+			wantErr:  domain.ErrNotATable,
+			wantMsgs: []string{`config.toml:1: top-level key "inclde" is not a profile table (got array;`},
+		},
+		{
+			name: "include_if の未知のキー",
+			content: `[[include_if]]
+pathh = "a.toml"
+env = "HOME"
+
+[work]
+gh_config_dir = "/gh"
+`,
+			wantErr:  domain.ErrUnknownKey,
+			wantMsgs: []string{`config.toml:2: include_if: unknown key "pathh" (did you mean "path"?)`},
+		},
+		{
+			name: "複数の問題は行番号順にすべて報告する",
+			content: `[work]
+gh_config_dir = "/gh"
+usr = "octocat"
+
+[personal]
+gh_config_dir = "/gh"
+roott = "~/r"
+`,
+			wantErr: domain.ErrUnknownKey,
+			wantMsgs: []string{
+				`config.toml:3: profile "work": unknown key "usr" (did you mean "user"?)`,
+				`config.toml:7: profile "personal": unknown key "roott" (did you mean "root"?)`,
+			},
+		},
+		{
+			name: "型の違いと未知のキーを両方報告する",
+			content: `[work]
+gh_confg_dir = "/gh"
+abstract = "yes"
+env = { GH_PAGER = "cat" }
+`,
+			wantErr: domain.ErrInvalidType,
+			wantMsgs: []string{
+				`config.toml:2: profile "work": unknown key "gh_confg_dir" (did you mean "gh_config_dir"?)`,
+				`config.toml:3: profile "work": invalid type for key "abstract"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.toml")
+			writeConfig(t, path, tt.content)
+
+			_, err := config.NewLoader(path).Load()
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			// wantMsgs はこの順に現れること
+			msg := err.Error() + "\n"
+			pos := 0
+			for _, want := range tt.wantMsgs {
+				i := strings.Index(msg[pos:], want)
+				if i < 0 {
+					t.Errorf("err =\n%s\nwant containing (in order)\n%s", msg, want)
+					continue
+				}
+				pos += i + len(want)
+			}
+		})
+	}
+}
+
+func TestLoad_StrictValidationInIncludedFile(t *testing.T) {
+	dir := t.TempDir()
+	incPath := filepath.Join(dir, "work.toml")
+	writeConfig(t, incPath, "# work\n[work]\nhots = \"ghe.example.com\"\ngh_config_dir = \"/gh\"\n")
+	mainPath := filepath.Join(dir, "config.toml")
+	writeConfig(t, mainPath, "include = [\"work.toml\"]\n")
+
+	_, err := config.NewLoader(mainPath).Load()
+	want := incPath + `:3: profile "work": unknown key "hots" (did you mean "host"?)`
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("err = %v, want containing %q", err, want)
+	}
+}
+
+func TestLoad_Lenient(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, `future_option = true
+
+[[include_if]]
+path = "missing.toml"
+env = "GH_MREPO_TEST_UNSET_VARIABLE"
+priority = 1
+
+[work]
+gh_config_dir = "/gh"
+new_key = "x"
+
+[work.extra]
+note = "x"
+`)
+
+	loader := config.NewLoader(path)
+	if _, err := loader.Load(); !errors.Is(err, domain.ErrUnknownKey) {
+		t.Fatalf("strict: err = %v, want %v", err, domain.ErrUnknownKey)
+	}
+
+	loader.SetLenient(true)
+	profiles, err := loader.Load()
+	if err != nil {
+		t.Fatalf("lenient: unexpected error: %v", err)
+	}
+	if len(profiles) != 1 || profiles[0].Name != "work" {
+		t.Errorf("profiles = %+v, want only work", profiles)
+	}
+
+	// 型の違いは寛容モードでもエラー
+	writeConfig(t, path, "[work]\ngh_config_dir = \"/gh\"\nabstract = \"no\"\n")
+	if _, err := loader.Load(); !errors.Is(err, domain.ErrInvalidType) {
+		t.Errorf("lenient type error: err = %v, want %v", err, domain.ErrInvalidType)
+	}
+}
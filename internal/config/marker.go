@@ -0,0 +1,52 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+type markerFile struct {
+	Profile string `toml:"profile"`
+}
+
+// FindMarker は dir から親方向に .gh-mrepo.toml を探して読み込む。
+// 見つからない場合はゼロ値の Marker を返す。
+func FindMarker(dir string) (domain.Marker, error) {
+	if dir == "" {
+		return domain.Marker{}, nil
+	}
+
+	current := filepath.Clean(dir)
+	for {
+		path := filepath.Join(current, domain.MarkerFileName)
+		if _, err := os.Stat(path); err == nil {
+			return readMarker(path)
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return domain.Marker{}, fmt.Errorf("failed to stat marker %q: %w", path, err)
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return domain.Marker{}, nil
+		}
+		current = parent
+	}
+}
+
+func readMarker(path string) (domain.Marker, error) {
+	var m markerFile
+	if _, err := toml.DecodeFile(path, &m); err != nil {
+		return domain.Marker{}, fmt.Errorf("failed to load marker %q: %w", path, err)
+	}
+	if m.Profile == "" {
+		return domain.Marker{}, fmt.Errorf("marker %q: profile must not be empty", path)
+	}
+	return domain.Marker{Path: path, Profile: m.Profile}, nil
+}
@@ -0,0 +1,75 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestFindMarker_InParentDirectory(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "acme", "api")
+	sub := filepath.Join(repo, "cmd", "server")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeMarker(t, repo, `profile = "work"`)
+
+	m, err := config.FindMarker(sub)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Profile != "work" {
+		t.Errorf("Profile = %q, want %q", m.Profile, "work")
+	}
+	if m.Path != filepath.Join(repo, domain.MarkerFileName) {
+		t.Errorf("Path = %q", m.Path)
+	}
+}
+
+func TestFindMarker_NearestWins(t *testing.T) {
+	base := t.TempDir()
+	repo := filepath.Join(base, "acme", "api")
+	if err := os.MkdirAll(repo, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeMarker(t, base, `profile = "personal"`)
+	writeMarker(t, repo, `profile = "work"`)
+
+	m, err := config.FindMarker(repo)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Profile != "work" {
+		t.Errorf("Profile = %q, want %q", m.Profile, "work")
+	}
+}
+
+func TestFindMarker_NotFound(t *testing.T) {
+	m, err := config.FindMarker(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if m.Path != "" || m.Profile != "" {
+		t.Errorf("marker = %+v, want zero value", m)
+	}
+}
+
+func TestFindMarker_EmptyProfile(t *testing.T) {
+	dir := t.TempDir()
+	writeMarker(t, dir, `profile = ""`)
+
+	if _, err := config.FindMarker(dir); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func writeMarker(t *testing.T, dir, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, domain.MarkerFileName), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// MigrationFile は移行で書き込むファイル。
+type MigrationFile struct {
+	Path    string
+	Content []byte
+	// Mode は新規作成時のパーミッション
+	Mode os.FileMode
+}
+
+// MigratedAccount はアカウントと、その移行先。
+type MigratedAccount struct {
+	Account
+	// Dir は新しい GH_CONFIG_DIR
+	Dir string
+	// Profile は追加または更新するプロファイル名
+	Profile string
+	// Existing は既存のプロファイルを書き換える場合 true
+	Existing bool
+}
+
+// Migration は複数アカウントが同居する gh 設定ディレクトリを、アカウントごとに分割する計画。
+// 元のディレクトリには手を加えない。
+type Migration struct {
+	Source   string
+	Accounts []MigratedAccount
+	Files    []MigrationFile
+}
+
+// PlanMigration は source の hosts.yml を読み、アカウントごとに ~/.config/gh-<name> を作る計画を立てる。
+// 各ディレクトリにはそのアカウントのホスト/ユーザー設定と config.yml をコピーする。
+// source の同じアカウントを指す既存プロファイルは gh_config_dir を書き換え、無ければプロファイルを追加する。
+func PlanMigration(source, home, configPath string) (*Migration, error) {
+	source = filepath.Clean(source)
+	data, err := os.ReadFile(filepath.Join(source, "hosts.yml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hosts.yml: %w", err)
+	}
+	var rawHosts map[string]map[string]any
+	if err := yaml.Unmarshal(data, &rawHosts); err != nil {
+		return nil, fmt.Errorf("failed to parse hosts.yml: %w", err)
+	}
+	accounts, err := accountsIn(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no logged-in accounts in %s", filepath.Join(source, "hosts.yml"))
+	}
+	ghConfig, err := os.ReadFile(filepath.Join(source, "config.yml"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	configExists := true
+	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
+		configExists = false
+	}
+	editor := NewEditor(configPath).Staged()
+	var existing []domain.Profile
+	if configExists {
+		if existing, err = editor.loader.Load(); err != nil {
+			return nil, err
+		}
+	}
+	used := make(map[string]bool, len(existing))
+	for _, p := range existing {
+		used[p.Name] = true
+	}
+
+	m := &Migration{Source: source}
+	var added []domain.Profile
+	// dirs は移行先ディレクトリと、そこに移すアカウント
+	dirs := make(map[string]Account, len(accounts))
+	for _, a := range accounts {
+		migrated := MigratedAccount{Account: a}
+		existingProfile, ok := profileForAccount(existing, rawHosts, a)
+		// 新しいプロファイルのディレクトリは重複を避けたプロファイル名から作る
+		name := profileNameFor(a)
+		if ok {
+			migrated.Profile, migrated.Existing = existingProfile.Name, true
+		} else {
+			migrated.Profile = uniqueName(name, used)
+			name = migrated.Profile
+		}
+
+		dir := filepath.Join(home, ".config", "gh-"+name)
+		if prev, ok := dirs[dir]; ok {
+			return nil, fmt.Errorf("%s@%s and %s@%s would both be moved to %s; set gh_config_dir of one of their profiles by hand",
+				prev.User, prev.Host, a.User, a.Host, dir)
+		}
+		dirs[dir] = a
+		migrated.Dir = dir
+		if _, err := os.Stat(filepath.Join(dir, "hosts.yml")); err == nil {
+			return nil, fmt.Errorf("%s already has a hosts.yml; move it away or log in there directly", dir)
+		}
+		hosts, err := yaml.Marshal(splitHostEntry(rawHosts[a.Host], a.Host, a.User))
+		if err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, MigrationFile{Path: filepath.Join(dir, "hosts.yml"), Content: hosts, Mode: 0o600})
+		if ghConfig != nil {
+			m.Files = append(m.Files, MigrationFile{Path: filepath.Join(dir, "config.yml"), Content: ghConfig, Mode: 0o600})
+		}
+
+		if migrated.Existing {
+			p := existingProfile
+			if err := editor.Set(p.Name, "gh_config_dir", compressHome(dir, home)); err != nil {
+				return nil, err
+			}
+			if p.User != "" {
+				if err := editor.Unset(p.Name, "user"); err != nil {
+					return nil, err
+				}
+			}
+		} else {
+			p := domain.Profile{Name: migrated.Profile, GHConfigDir: compressHome(dir, home)}
+			if a.Host != domain.DefaultHost {
+				p.Host = a.Host
+			}
+			if configExists {
+				values := []KeyValue{{Key: "gh_config_dir", Value: p.GHConfigDir}}
+				if p.Host != "" {
+					values = append(values, KeyValue{Key: "host", Value: p.Host})
+				}
+				if err := editor.Add(p.Name, "", values); err != nil {
+					return nil, err
+				}
+			}
+			added = append(added, p)
+		}
+		m.Accounts = append(m.Accounts, migrated)
+	}
+
+	if configExists {
+		for _, c := range editor.Changes() {
+			m.Files = append(m.Files, MigrationFile{Path: c.Path, Content: c.Content, Mode: 0o644})
+		}
+	} else {
+		content := RenderConfig(added)
+		if _, err := NewLoader(configPath).withOverride(configPath, content).Load(); err != nil {
+			return nil, err
+		}
+		m.Files = append(m.Files, MigrationFile{Path: configPath, Content: content, Mode: 0o644})
+	}
+	return m, nil
+}
+
+// profileForAccount は source の同じアカウントを指している既存プロファイルを探す。
+// user 未指定のプロファイルはそのホストのアクティブユーザーを指しているとみなす。
+func profileForAccount(profiles []domain.Profile, rawHosts map[string]map[string]any, a Account) (domain.Profile, bool) {
+	active, _ := rawHosts[a.Host]["user"].(string)
+	for _, p := range profiles {
+		if filepath.Clean(p.GHConfigDir) != a.GHConfigDir || p.HostName() != a.Host {
+			continue
+		}
+		if p.User == a.User || (p.User == "" && active == a.User) {
+			return p, true
+		}
+	}
+	return domain.Profile{}, false
+}
+
+// splitHostEntry は hosts.yml のホストエントリから user 1人分のエントリを作る。
+// git_protocol などホスト共通の設定はそのまま引き継ぐ。
+func splitHostEntry(entry map[string]any, host, user string) map[string]any {
+	out := make(map[string]any, len(entry))
+	for k, v := range entry {
+		if k != "users" && k != "user" && k != "oauth_token" {
+			out[k] = v
+		}
+	}
+
+	var token any
+	userEntry := map[string]any{}
+	if users, ok := entry["users"].(map[string]any); ok {
+		if u, ok := users[user].(map[string]any); ok {
+			userEntry = u
+			token = u["oauth_token"]
+		}
+	}
+	if token == nil && entry["user"] == user {
+		token = entry["oauth_token"]
+	}
+	if token != nil {
+		out["oauth_token"] = token
+	}
+	out["users"] = map[string]any{user: userEntry}
+	out["user"] = user
+	return map[string]any{host: out}
+}
+
+var tokenLineRe = regexp.MustCompile(`(?m)^(\s*oauth_token:\s*)\S+`)
+
+// Preview は書き込み予定のファイルを内容付きで出力する。トークンは伏せる。
+func (m *Migration) Preview(w io.Writer) {
+	for _, f := range m.Files {
+		state := "create"
+		if _, err := os.Stat(f.Path); err == nil {
+			state = "update"
+		}
+		_, _ = fmt.Fprintf(w, "==> %s (%s)\n", f.Path, state)
+		content := tokenLineRe.ReplaceAll(f.Content, []byte("${1}********"))
+		_, _ = w.Write(content)
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			_, _ = fmt.Fprintln(w)
+		}
+		_, _ = fmt.Fprintln(w)
+	}
+}
+
+// Apply は計画どおりにファイルを書き込む。gh の設定ディレクトリを先に作り、設定ファイルは最後に更新する。
+func (m *Migration) Apply() error {
+	for _, f := range m.Files {
+		dirMode := os.FileMode(0o755)
+		if f.Mode&0o077 == 0 {
+			dirMode = 0o700
+		}
+		if err := os.MkdirAll(filepath.Dir(f.Path), dirMode); err != nil {
+			return fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := writeFileAtomic(f.Path, f.Content, f.Mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
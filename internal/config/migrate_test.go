@@ -0,0 +1,222 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+)
+
+const sharedHostsYml = `github.com:
+    git_protocol: ssh
+    users:
+        octocat:
+            oauth_token: gho_personal
+        octocat-work:
+            oauth_token: gho_work
+    user: octocat
+    oauth_token: gho_personal
+ghe.example.com:
+    users:
+        taro:
+    user: taro
+`
+
+func setupSharedGH(t *testing.T) (home, source string) {
+	t.Helper()
+	home = t.TempDir()
+	t.Setenv("HOME", home)
+	source = filepath.Join(home, ".config", "gh")
+	mkGHDir(t, source, sharedHostsYml)
+	if err := os.WriteFile(filepath.Join(source, "config.yml"), []byte("git_protocol: ssh\neditor: vim\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return home, source
+}
+
+func findFile(m *config.Migration, path string) (config.MigrationFile, bool) {
+	for _, f := range m.Files {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return config.MigrationFile{}, false
+}
+
+func TestPlanMigration_ExistingConfig(t *testing.T) {
+	home, source := setupSharedGH(t)
+	configPath := filepath.Join(home, ".config", "gh-mrepo", "config.toml")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	original := `# my profiles
+[work]
+gh_config_dir = "~/.config/gh" # shared
+user = "octocat-work"
+root = "~/repos/work"
+`
+	writeConfig(t, configPath, original)
+
+	m, err := config.PlanMigration(source, home, configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// 計画の段階では何も書き込まない
+	if got := readConfig(t, configPath); got != original {
+		t.Errorf("config changed before Apply:\n%s", got)
+	}
+
+	if len(m.Accounts) != 3 {
+		t.Fatalf("len(Accounts) = %d, want 3", len(m.Accounts))
+	}
+	work := m.Accounts[2]
+	if work.User != "octocat-work" || work.Profile != "work" || !work.Existing {
+		t.Errorf("work account = %+v, want existing profile work", work)
+	}
+
+	var preview bytes.Buffer
+	m.Preview(&preview)
+	if strings.Contains(preview.String(), "gho_") {
+		t.Errorf("preview leaks tokens:\n%s", preview.String())
+	}
+
+	if err := m.Apply(); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	workHosts, err := config.ReadHosts(filepath.Join(home, ".config", "gh-octocat-work"))
+	if err != nil {
+		t.Fatalf("ReadHosts: %v", err)
+	}
+	entry := workHosts["github.com"]
+	if entry.User != "octocat-work" || entry.GitProtocol != "ssh" || len(entry.Users) != 1 || entry.Users["octocat-work"].OAuthToken != "gho_work" {
+		t.Errorf("work hosts.yml entry = %+v", entry)
+	}
+	if _, ok := workHosts["ghe.example.com"]; ok {
+		t.Error("other hosts must not be copied")
+	}
+	if data, err := os.ReadFile(filepath.Join(home, ".config", "gh-octocat-work", "config.yml")); err != nil || string(data) != "git_protocol: ssh\neditor: vim\n" {
+		t.Errorf("config.yml = %q, %v", data, err)
+	}
+	info, err := os.Stat(filepath.Join(home, ".config", "gh-octocat-work", "hosts.yml"))
+	if err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("hosts.yml mode = %v, %v", info.Mode().Perm(), err)
+	}
+
+	wantConfig := `# my profiles
+[work]
+gh_config_dir = "~/.config/gh-octocat-work" # shared
+root = "~/repos/work"
+
+[taro-ghe]
+gh_config_dir = "~/.config/gh-taro-ghe"
+host = "ghe.example.com"
+
+[octocat]
+gh_config_dir = "~/.config/gh-octocat"
+`
+	if got := readConfig(t, configPath); got != wantConfig {
+		t.Errorf("config =\n%s\nwant\n%s", got, wantConfig)
+	}
+
+	// 元の hosts.yml はそのまま
+	if data, _ := os.ReadFile(filepath.Join(source, "hosts.yml")); string(data) != sharedHostsYml {
+		t.Error("source hosts.yml was modified")
+	}
+}
+
+func TestPlanMigration_NewConfig(t *testing.T) {
+	home, source := setupSharedGH(t)
+	configPath := filepath.Join(home, ".config", "gh-mrepo", "config.toml")
+
+	m, err := config.PlanMigration(source, home, configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f, ok := findFile(m, configPath)
+	if !ok {
+		t.Fatal("config.toml is not in the plan")
+	}
+	for _, want := range []string{"[octocat]", "[octocat-work]", "[taro-ghe]", `host = "ghe.example.com"`} {
+		if !strings.Contains(string(f.Content), want) {
+			t.Errorf("config.toml missing %q:\n%s", want, f.Content)
+		}
+	}
+	// トークンがファイルに無い (キーチェーン保存) アカウントでも users エントリは作る
+	taro, ok := findFile(m, filepath.Join(home, ".config", "gh-taro-ghe", "hosts.yml"))
+	if !ok || !strings.Contains(string(taro.Content), "taro:") || strings.Contains(string(taro.Content), "oauth_token") {
+		t.Errorf("taro hosts.yml = %s", taro.Content)
+	}
+}
+
+func TestPlanMigration_TargetExists(t *testing.T) {
+	home, source := setupSharedGH(t)
+	mkGHDir(t, filepath.Join(home, ".config", "gh-octocat"), "github.com:\n    user: octocat\n")
+
+	_, err := config.PlanMigration(source, home, filepath.Join(home, "config.toml"))
+	if err == nil || !strings.Contains(err.Error(), "gh-octocat") {
+		t.Fatalf("err = %v, want error about existing directory", err)
+	}
+}
+
+const sameLabelHostsYml = `github.acme.com:
+    users:
+        taro:
+            oauth_token: gho_acme
+    user: taro
+github.foo.com:
+    users:
+        taro:
+            oauth_token: gho_foo
+    user: taro
+`
+
+func TestPlanMigration_HostsWithSameFirstLabel(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	source := filepath.Join(home, ".config", "gh")
+	mkGHDir(t, source, sameLabelHostsYml)
+	configPath := filepath.Join(home, ".config", "gh-mrepo", "config.toml")
+
+	m, err := config.PlanMigration(source, home, configPath)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{"taro-github": "github.acme.com", "taro-github-2": "github.foo.com"}
+	for _, a := range m.Accounts {
+		if want[a.Profile] != a.Host || a.Dir != filepath.Join(home, ".config", "gh-"+a.Profile) {
+			t.Errorf("account = %s -> %s (%s)", a.Host, a.Profile, a.Dir)
+		}
+	}
+	for name, host := range want {
+		f, ok := findFile(m, filepath.Join(home, ".config", "gh-"+name, "hosts.yml"))
+		if !ok || !strings.Contains(string(f.Content), host) {
+			t.Errorf("hosts.yml for %s = %s", name, f.Content)
+		}
+	}
+}
+
+func TestPlanMigration_SameTargetForExistingProfiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	source := filepath.Join(home, ".config", "gh")
+	mkGHDir(t, source, sameLabelHostsYml)
+	configPath := filepath.Join(home, "config.toml")
+	writeConfig(t, configPath, `[acme]
+gh_config_dir = "~/.config/gh"
+host = "github.acme.com"
+
+[foo]
+gh_config_dir = "~/.config/gh"
+host = "github.foo.com"
+`)
+
+	_, err := config.PlanMigration(source, home, configPath)
+	if err == nil || !strings.Contains(err.Error(), "gh-taro-github") {
+		t.Fatalf("err = %v, want error about the shared target directory", err)
+	}
+}
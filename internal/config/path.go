@@ -0,0 +1,35 @@
+package config
+
+import (
+	"path/filepath"
+)
+
+// PathEnv は設定ファイルのパスを指定する環境変数。
+const PathEnv = "GH_MREPO_CONFIG"
+
+// ResolvePath は設定ファイルのパスを次の順に決める。
+//
+//  1. --config フラグ (flag)
+//  2. $GH_MREPO_CONFIG
+//  3. $XDG_CONFIG_HOME/gh-mrepo/config.toml (XDG_CONFIG_HOME が絶対パスの場合)
+//  4. ~/.config/gh-mrepo/config.toml
+//
+// ~ を展開し、相対パスはカレントディレクトリ基準の絶対パスにする。
+func ResolvePath(flag string, getenv func(string) string, home string) (string, error) {
+	path := flag
+	if path == "" {
+		path = getenv(PathEnv)
+	}
+	if path == "" {
+		if xdg := getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+			return filepath.Join(xdg, "gh-mrepo", "config.toml"), nil
+		}
+		return filepath.Join(home, ".config", "gh-mrepo", "config.toml"), nil
+	}
+
+	expanded, err := expandTilde(path)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(expanded)
+}
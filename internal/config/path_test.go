@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+)
+
+func TestResolvePath(t *testing.T) {
+	home, _ := os.UserHomeDir()
+	wd, _ := os.Getwd()
+
+	tests := []struct {
+		name string
+		flag string
+		env  map[string]string
+		want string
+	}{
+		{
+			name: "デフォルト",
+			want: "/home/u/.config/gh-mrepo/config.toml",
+		},
+		{
+			name: "XDG_CONFIG_HOME",
+			env:  map[string]string{"XDG_CONFIG_HOME": "/xdg"},
+			want: "/xdg/gh-mrepo/config.toml",
+		},
+		{
+			name: "相対パスの XDG_CONFIG_HOME は無視する",
+			env:  map[string]string{"XDG_CONFIG_HOME": "xdg"},
+			want: "/home/u/.config/gh-mrepo/config.toml",
+		},
+		{
+			name: "GH_MREPO_CONFIG は XDG_CONFIG_HOME より優先",
+			env:  map[string]string{"XDG_CONFIG_HOME": "/xdg", "GH_MREPO_CONFIG": "/etc/gh-mrepo.toml"},
+			want: "/etc/gh-mrepo.toml",
+		},
+		{
+			name: "GH_MREPO_CONFIG のチルダ展開",
+			env:  map[string]string{"GH_MREPO_CONFIG": "~/dotfiles/gh-mrepo.toml"},
+			want: filepath.Join(home, "dotfiles", "gh-mrepo.toml"),
+		},
+		{
+			name: "--config が最優先",
+			flag: "/flag.toml",
+			env:  map[string]string{"GH_MREPO_CONFIG": "/env.toml"},
+			want: "/flag.toml",
+		},
+		{
+			name: "相対パスはカレントディレクトリ基準",
+			flag: "conf/gh-mrepo.toml",
+			want: filepath.Join(wd, "conf", "gh-mrepo.toml"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			getenv := func(key string) string { return tt.env[key] }
+			got, err := config.ResolvePath(tt.flag, getenv, "/home/u")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ResolvePath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+)
+
+// SchemaID は JSON Schema の $id。
+const SchemaID = "https://github.com/sarrrrry/gh-mrepo/config.schema.json"
+
+// JSONSchema は config.toml の JSON Schema を返す。
+// プロファイルのキーは profileKeys から生成するので、キーを追加すれば自動で反映される。
+func JSONSchema() ([]byte, error) {
+	profile := map[string]any{
+		"type":                 "object",
+		"properties":           schemaProperties(profileKeys),
+		"additionalProperties": false,
+	}
+	schema := map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"$id":         SchemaID,
+		"title":       "gh-mrepo config",
+		"description": "Profiles for gh-mrepo. Every table other than include and include_if is a profile.",
+		"type":        "object",
+		"properties": map[string]any{
+			"include": map[string]any{
+				"type":        "array",
+				"items":       map[string]any{"type": "string"},
+				"description": "Additional config files to load. Relative paths are resolved from this file; glob patterns are allowed.",
+			},
+			"include_if": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"type":                 "object",
+					"properties":           schemaProperties(includeIfSpecs),
+					"required":             []string{"path"},
+					"additionalProperties": false,
+				},
+				"description": "Files to load only when every condition matches.",
+			},
+		},
+		"additionalProperties": map[string]any{"$ref": "#/$defs/profile"},
+		"$defs":                map[string]any{"profile": profile},
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+// schemaType は keyKind に対応する JSON Schema の型定義を返す。
+func schemaType(kind keyKind) map[string]any {
+	str := map[string]any{"type": "string"}
+	switch kind {
+	case kindBool:
+		return map[string]any{"type": "boolean"}
+	case kindInt:
+		return map[string]any{"type": "integer", "minimum": 0}
+	case kindStringList:
+		return map[string]any{"type": "array", "items": str}
+	case kindStringMap:
+		return map[string]any{"type": "object", "additionalProperties": str}
+	case kindListMap:
+		return map[string]any{"type": "object", "additionalProperties": schemaType(kindStringList)}
+	default:
+		return str
+	}
+}
+
+func schemaProperties(specs []keySpec) map[string]any {
+	props := make(map[string]any, len(specs))
+	for _, spec := range specs {
+		prop := schemaType(spec.Kind)
+		prop["description"] = spec.Description
+		props[spec.Name] = prop
+	}
+	return props
+}
@@ -0,0 +1,49 @@
+package config_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := config.JSONSchema()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var schema struct {
+		Schema string `json:"$schema"`
+		Defs   struct {
+			Profile struct {
+				Properties           map[string]struct{ Type string } `json:"properties"`
+				AdditionalProperties bool                             `json:"additionalProperties"`
+			} `json:"profile"`
+		} `json:"$defs"`
+		Properties map[string]json.RawMessage `json:"properties"`
+	}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, data)
+	}
+
+	if schema.Schema == "" {
+		t.Error("$schema is missing")
+	}
+	props := schema.Defs.Profile.Properties
+	for key, wantType := range map[string]string{
+		"gh_config_dir": "string", "root": "string", "extends": "string", "abstract": "boolean",
+		"tags": "array", "env": "object", "defaults": "object",
+	} {
+		if props[key].Type != wantType {
+			t.Errorf("profile.%s type = %q, want %q", key, props[key].Type, wantType)
+		}
+	}
+	if schema.Defs.Profile.AdditionalProperties {
+		t.Error("profile must not allow additional properties")
+	}
+	for _, key := range []string{"include", "include_if"} {
+		if _, ok := schema.Properties[key]; !ok {
+			t.Errorf("top-level %s is missing", key)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ResolvedField は解決後の1項目と、その値が定義されている場所。
+type ResolvedField struct {
+	Key string `json:"key"`
+	// Entry は env などのテーブルの項目のキー
+	Entry string `json:"entry,omitempty"`
+	// Value は環境変数とチルダを展開した値
+	Value string `json:"value,omitempty"`
+	// Values は配列の項目 (tags) の値
+	Values []string `json:"values,omitempty"`
+	// File は値が書かれているファイル
+	File string `json:"file"`
+	// From は値を引き継いだ親プロファイル。自身で定義していれば空
+	From string `json:"from,omitempty"`
+	// Exists はパスの項目でだけ設定し、そのパスが存在するかを表す
+	Exists *bool `json:"exists,omitempty"`
+}
+
+// ResolvedProfile は include と extends を解決したプロファイル。
+type ResolvedProfile struct {
+	Name string `json:"name"`
+	File string `json:"file"`
+	// Extends は extends でたどった親プロファイル (近い順)
+	Extends []string        `json:"extends,omitempty"`
+	Fields  []ResolvedField `json:"fields"`
+}
+
+// Resolve は Load と同じ解決をしたプロファイルを、項目ごとの定義元付きで返す。
+// abstract なプロファイルは含めない。
+func (l *Loader) Resolve() ([]ResolvedProfile, error) {
+	// 検証とエラーの報告は Load に任せる
+	if _, err := l.Load(); err != nil {
+		return nil, err
+	}
+	merged, _, err := l.collect()
+	if err != nil {
+		return nil, err
+	}
+	for name, src := range merged {
+		entry, err := interpolateEntry(src.entry)
+		if err != nil {
+			return nil, fmt.Errorf("profile %q in %q: %w", name, src.file, err)
+		}
+		merged[name] = sourcedEntry{entry: entry, file: src.file}
+	}
+
+	names := make([]string, 0, len(merged))
+	for name, src := range merged {
+		if !src.entry.Abstract {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	resolved := make([]ResolvedProfile, 0, len(names))
+	for _, name := range names {
+		// Load で循環と未定義の親は検出済み
+		var parents []string
+		for parent := merged[name].entry.Extends; parent != ""; parent = merged[parent].entry.Extends {
+			parents = append(parents, parent)
+		}
+		rp := ResolvedProfile{Name: name, File: merged[name].file, Extends: parents}
+		for _, spec := range profileKeys {
+			if spec.Kind == kindBool || spec.Name == "extends" {
+				continue
+			}
+			chain := append([]string{name}, parents...)
+			if spec.Own {
+				chain = chain[:1]
+			}
+			if spec.Kind == kindStringMap || spec.Kind == kindListMap {
+				rp.Fields = append(rp.Fields, resolveTable(spec.Name, chain, merged)...)
+				continue
+			}
+			for _, owner := range chain {
+				v := merged[owner].entry.field(spec.Name)
+				if v.IsZero() {
+					continue
+				}
+				f := ResolvedField{Key: spec.Name, File: merged[owner].file}
+				f.set(v)
+				if owner != name {
+					f.From = owner
+				}
+				if spec.Path {
+					if f.Value, err = expandTilde(f.Value); err != nil {
+						return nil, err
+					}
+					_, statErr := os.Stat(f.Value)
+					exists := statErr == nil
+					f.Exists = &exists
+				}
+				rp.Fields = append(rp.Fields, f)
+				break
+			}
+		}
+		resolved = append(resolved, rp)
+	}
+	return resolved, nil
+}
+
+// resolveTable は env などのテーブルをキーごとに解決する。
+// 各キーは chain (自身、親、その親…) のうち最初に定義しているプロファイルの値を使う。
+func resolveTable(key string, chain []string, merged map[string]sourcedEntry) []ResolvedField {
+	var entries []string
+	for _, owner := range chain {
+		for it := merged[owner].entry.field(key).MapRange(); it.Next(); {
+			if k := it.Key().String(); !slices.Contains(entries, k) {
+				entries = append(entries, k)
+			}
+		}
+	}
+	sort.Strings(entries)
+
+	fields := make([]ResolvedField, 0, len(entries))
+	for _, entry := range entries {
+		for i, owner := range chain {
+			v := merged[owner].entry.field(key).MapIndex(reflect.ValueOf(entry))
+			if !v.IsValid() {
+				continue
+			}
+			f := ResolvedField{Key: key, Entry: entry, File: merged[owner].file}
+			f.set(v)
+			if i > 0 {
+				f.From = owner
+			}
+			fields = append(fields, f)
+			break
+		}
+	}
+	return fields
+}
+
+// set は文字列、整数、文字列の配列のいずれかの値を設定する。
+func (f *ResolvedField) set(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Slice:
+		f.Values = v.Interface().([]string)
+	case reflect.Int:
+		f.Value = strconv.FormatInt(v.Int(), 10)
+	default:
+		f.Value = v.String()
+	}
+}
+
+// field は toml タグが key の項目を返す。
+func (e profileEntry) field(key string) reflect.Value {
+	v := reflect.ValueOf(e)
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).Tag.Get("toml") == key {
+			return v.Field(i)
+		}
+	}
+	panic("config: no profileEntry field for key " + key)
+}
+
+// RenderResolved は解決済みのプロファイルを TOML として書き出す。
+// 定義元とパスの有無は行末のコメントに書く。ファイル名の home は ~ に縮める。
+func RenderResolved(profiles []ResolvedProfile, home string) []byte {
+	var b strings.Builder
+	for i, p := range profiles {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		if len(p.Extends) > 0 {
+			fmt.Fprintf(&b, "# extends: %s\n", strings.Join(p.Extends, " -> "))
+		}
+		fmt.Fprintf(&b, "[%s] # %s\n", formatKey(p.Name), compressHome(p.File, home))
+		for _, f := range p.Fields {
+			note := compressHome(f.File, home)
+			if f.From != "" {
+				note += fmt.Sprintf(" (from %s)", f.From)
+			}
+			if f.Exists != nil {
+				if *f.Exists {
+					note += "; exists"
+				} else {
+					note += "; missing"
+				}
+			}
+			literal := formatString(f.Value)
+			if f.Values != nil {
+				literal = formatStringList(f.Values)
+			} else if spec, _ := lookupKey(f.Key); spec.Kind == kindInt {
+				literal = f.Value
+			}
+			key := formatKey(f.Key)
+			if f.Entry != "" {
+				key += "." + formatKey(f.Entry)
+			}
+			fmt.Fprintf(&b, "%s = %s # %s\n", key, literal, note)
+		}
+	}
+	return []byte(b.String())
+}
@@ -0,0 +1,119 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+)
+
+func TestLoader_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("GH_MREPO_TEST_ORG", "acme")
+	if err := os.MkdirAll(filepath.Join(dir, ".config", "gh-work"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	basePath := filepath.Join(dir, "base.toml")
+	writeConfig(t, basePath, `[base]
+abstract = true
+root = "~/repos/${GH_MREPO_TEST_ORG}"
+host = "ghe.example.com"
+tags = ["work", "ghe"]
+env = { GH_PAGER = "cat", NO_COLOR = "1" }
+`)
+	mainPath := filepath.Join(dir, "config.toml")
+	writeConfig(t, mainPath, `include = ["base.toml"]
+
+[work]
+extends = "base"
+gh_config_dir = "~/.config/gh-work"
+env = { GH_PAGER = "less" }
+`)
+
+	got, err := config.NewLoader(mainPath).Resolve()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 1 {
+		t.Fatalf("len = %d, want 1 (abstract profiles are excluded)", len(got))
+	}
+	work := got[0]
+	if work.Name != "work" || work.File != mainPath || len(work.Extends) != 1 || work.Extends[0] != "base" {
+		t.Errorf("profile = %+v", work)
+	}
+
+	exists, missing := true, false
+	want := []config.ResolvedField{
+		{Key: "gh_config_dir", Value: filepath.Join(dir, ".config", "gh-work"), File: mainPath, Exists: &exists},
+		{Key: "root", Value: filepath.Join(dir, "repos", "acme"), File: basePath, From: "base", Exists: &missing},
+		{Key: "host", Value: "ghe.example.com", File: basePath, From: "base"},
+		{Key: "env", Entry: "GH_PAGER", Value: "less", File: mainPath},
+		{Key: "env", Entry: "NO_COLOR", Value: "1", File: basePath, From: "base"},
+		{Key: "tags", Values: []string{"work", "ghe"}, File: basePath, From: "base"},
+	}
+	if len(work.Fields) != len(want) {
+		t.Fatalf("fields = %+v, want %d fields", work.Fields, len(want))
+	}
+	for i, w := range want {
+		f := work.Fields[i]
+		if f.Key != w.Key || f.Entry != w.Entry || f.Value != w.Value || !slices.Equal(f.Values, w.Values) || f.File != w.File || f.From != w.From {
+			t.Errorf("fields[%d] = %+v, want %+v", i, f, w)
+		}
+		if (f.Exists == nil) != (w.Exists == nil) || f.Exists != nil && *f.Exists != *w.Exists {
+			t.Errorf("fields[%d].Exists = %v, want %v", i, f.Exists, w.Exists)
+		}
+	}
+}
+
+func TestLoader_ResolveInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.toml")
+	writeConfig(t, path, "[work]\nextends = \"missing\"\ngh_config_dir = \"/gh\"\n")
+
+	if _, err := config.NewLoader(path).Resolve(); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestRenderResolved(t *testing.T) {
+	exists, missing := true, false
+	profiles := []config.ResolvedProfile{
+		{
+			Name:    "work",
+			File:    "/home/me/.config/gh-mrepo/config.toml",
+			Extends: []string{"base"},
+			Fields: []config.ResolvedField{
+				{Key: "gh_config_dir", Value: "/home/me/.config/gh-work", File: "/home/me/.config/gh-mrepo/config.toml", Exists: &exists},
+				{Key: "root", Value: "/home/me/repos/work", File: "/etc/base.toml", From: "base", Exists: &missing},
+				{Key: "host", Value: "ghe.example.com", File: "/etc/base.toml", From: "base"},
+				{Key: "scan_depth", Value: "3", File: "/etc/base.toml", From: "base"},
+				{Key: "defaults", Entry: "repo list", Values: []string{"--limit", "200"}, File: "/home/me/.config/gh-mrepo/config.toml"},
+				{Key: "tags", Values: []string{"work"}, File: "/home/me/.config/gh-mrepo/config.toml"},
+			},
+		},
+		{
+			Name:   "my.profile",
+			File:   "/home/me/.config/gh-mrepo/config.toml",
+			Fields: []config.ResolvedField{{Key: "user", Value: `a"b`, File: "/home/me/.config/gh-mrepo/config.toml"}},
+		},
+	}
+
+	got := string(config.RenderResolved(profiles, "/home/me"))
+	want := `# extends: base
+[work] # ~/.config/gh-mrepo/config.toml
+gh_config_dir = "/home/me/.config/gh-work" # ~/.config/gh-mrepo/config.toml; exists
+root = "/home/me/repos/work" # /etc/base.toml (from base); missing
+host = "ghe.example.com" # /etc/base.toml (from base)
+scan_depth = 3 # /etc/base.toml (from base)
+defaults."repo list" = ["--limit", "200"] # ~/.config/gh-mrepo/config.toml
+tags = ["work"] # ~/.config/gh-mrepo/config.toml
+
+["my.profile"] # ~/.config/gh-mrepo/config.toml
+user = "a\"b" # ~/.config/gh-mrepo/config.toml
+`
+	if got != want {
+		t.Errorf("RenderResolved =\n%s\nwant\n%s", got, want)
+	}
+}
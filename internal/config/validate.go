@@ -0,0 +1,83 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// ValidationError は設定ファイル中の1か所の問題を、ファイル名と行番号付きで表す。
+type ValidationError struct {
+	File string
+	// Line は1始まりの行番号。特定できない場合は 0
+	Line int
+	// Key は問題のあるキーのパス (例: ["work", "gh_confg_dir"])
+	Key []string
+	// Err は domain.ErrUnknownKey などの種別
+	Err error
+	// Detail は補足 (候補や型の説明)
+	Detail string
+}
+
+func (e *ValidationError) Error() string {
+	loc := e.File
+	if e.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", e.File, e.Line)
+	}
+	detail := ""
+	if e.Detail != "" {
+		detail = " (" + e.Detail + ")"
+	}
+	if len(e.Key) == 1 {
+		return fmt.Sprintf("%s: top-level key %q %v%s", loc, e.Key[0], e.Err, detail)
+	}
+	subject := fmt.Sprintf("profile %q", e.Key[0])
+	if e.Key[0] == "include_if" {
+		subject = "include_if"
+	}
+	return fmt.Sprintf("%s: %s: %v %q%s", loc, subject, e.Err, strings.Join(e.Key[1:], "."), detail)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// didYouMean は candidates から key に近いものを "did you mean" 形式で返す。
+func didYouMean(key string, candidates []string) string {
+	s := domain.Suggest(key, candidates)
+	if len(s) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("did you mean %q?", s[0])
+}
+
+// joinValidationErrors は doc から行番号を補って行番号順に並べ、1つのエラーにまとめる。
+func joinValidationErrors(path string, doc *document, issues []*ValidationError) error {
+	for _, is := range issues {
+		is.File = path
+		is.Line = doc.line(is.Key)
+	}
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	errs := make([]error, len(issues))
+	for i, is := range issues {
+		errs[i] = is
+	}
+	return errors.Join(errs...)
+}
+
+// typeName は toml.MetaData.Type の型名を表示用にする。
+func typeName(tomlType string) string {
+	switch tomlType {
+	case "Bool":
+		return "boolean"
+	case "Hash":
+		return "table"
+	case "ArrayHash":
+		return "array of tables"
+	default:
+		return strings.ToLower(tomlType)
+	}
+}
@@ -8,4 +8,15 @@ var (
 	ErrEmptyName           = errors.New("profile name must not be empty")
 	ErrConfigAlreadyExists = errors.New("config file already exists")
 	ErrDuplicateProfile    = errors.New("duplicate profile name")
+	ErrExtendsCycle        = errors.New("extends cycle")
+	ErrUnknownParent       = errors.New("extends an unknown profile")
+	ErrUndefinedVariable   = errors.New("environment variable is not set")
+	ErrUnknownKey          = errors.New("unknown key")
+	ErrInvalidType         = errors.New("invalid type for key")
+	ErrNotATable           = errors.New("is not a profile table")
+	ErrNestedInclude       = errors.New("is only allowed in the main config file")
+	ErrUnknownTag          = errors.New("unknown tag")
+	ErrNoMatchingProfiles  = errors.New("no profiles match the tag filter")
+	ErrProfileNotFound     = errors.New("profile not found")
+	ErrAliasConflict       = errors.New("alias conflicts with another profile")
 )
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"slices"
+	"strings"
+)
+
+// WithDefaults は gh の引数 args (サブコマンドを含む) に、プロファイルの defaults のうち
+// 最も長く一致するサブコマンドの既定の引数を補って返す。
+// 既定の引数はサブコマンドの直後に置くので、同じフラグが重なった場合は後ろの args が勝つ。
+// さらに args と同名のフラグは既定の引数から除く。"--" 以降 (git に渡す引数) も同様に扱う。
+func (p Profile) WithDefaults(args []string) []string {
+	n, defaults := p.defaultsFor(args)
+	if len(defaults) == 0 {
+		return args
+	}
+	userArgs, userExtra, userSep := splitDashDash(args[n:])
+	defArgs, defExtra, defSep := splitDashDash(defaults)
+
+	merged := slices.Clone(args[:n])
+	merged = append(merged, withoutOverridden(defArgs, userArgs)...)
+	merged = append(merged, userArgs...)
+	if userSep || defSep {
+		merged = append(merged, "--")
+		merged = append(merged, withoutOverridden(defExtra, userExtra)...)
+		merged = append(merged, userExtra...)
+	}
+	return merged
+}
+
+// defaultsFor は args の先頭に最も長く一致する defaults のキーの語数と、その既定の引数を返す。
+func (p Profile) defaultsFor(args []string) (int, []string) {
+	best := 0
+	var defaults []string
+	for key, values := range p.Defaults {
+		words := strings.Fields(key)
+		if len(words) > best && len(words) <= len(args) && slices.Equal(words, args[:len(words)]) {
+			best = len(words)
+			defaults = values
+		}
+	}
+	return best, defaults
+}
+
+// splitDashDash は args を "--" の前後に分ける。
+func splitDashDash(args []string) (before, after []string, found bool) {
+	i := slices.Index(args, "--")
+	if i < 0 {
+		return args, nil, false
+	}
+	return args[:i], args[i+1:], true
+}
+
+// withoutOverridden は defaults から user と同名のフラグ (と、その値) を除いて返す。
+// "--flag value" の value は、次の要素が "-" で始まらない場合にフラグの値とみなす。
+func withoutOverridden(defaults, user []string) []string {
+	var kept []string
+	for i := 0; i < len(defaults); i++ {
+		item := defaults[i : i+1]
+		name, _, hasValue := strings.Cut(defaults[i], "=")
+		isFlag := strings.HasPrefix(name, "-") && name != "-"
+		if isFlag && !hasValue && i+1 < len(defaults) && !strings.HasPrefix(defaults[i+1], "-") {
+			item = defaults[i : i+2]
+			i++
+		}
+		if isFlag && hasFlag(user, name) {
+			continue
+		}
+		kept = append(kept, item...)
+	}
+	return kept
+}
+
+// hasFlag は args に name または name=value があるかを返す。
+func hasFlag(args []string, name string) bool {
+	return slices.ContainsFunc(args, func(a string) bool {
+		return a == name || strings.HasPrefix(a, name+"=")
+	})
+}
@@ -0,0 +1,43 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestProfile_WithDefaults(t *testing.T) {
+	p := domain.Profile{Defaults: map[string][]string{
+		"repo":       {"--verbose"},
+		"repo list":  {"--limit", "200", "--no-archived"},
+		"repo clone": {"--", "--filter=blob:none", "--depth", "1"},
+		"pr create":  {"--draft"},
+	}}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "既定の引数を補う", args: []string{"repo", "list"}, want: "repo list --limit 200 --no-archived"},
+		{name: "長く一致するキーを使う", args: []string{"repo", "view"}, want: "repo --verbose view"},
+		{name: "ユーザーの引数が勝つ", args: []string{"repo", "list", "octo", "--limit", "5"}, want: "repo list --no-archived octo --limit 5"},
+		{name: "フラグ=値の形でも勝つ", args: []string{"repo", "list", "--limit=5"}, want: "repo list --no-archived --limit=5"},
+		{
+			name: "-- 以降を合わせる",
+			args: []string{"repo", "clone", "o/r", "--", "--depth", "10"},
+			want: "repo clone o/r -- --filter=blob:none --depth 10",
+		},
+		{name: "ユーザーが -- を書かなくても -- を補う", args: []string{"repo", "clone", "o/r"}, want: "repo clone o/r -- --filter=blob:none --depth 1"},
+		{name: "一致しない", args: []string{"issue", "list"}, want: "issue list"},
+		{name: "サブコマンドの途中までは一致しない", args: []string{"pr"}, want: "pr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(p.WithDefaults(tt.args), " ")
+			if got != tt.want {
+				t.Errorf("WithDefaults(%v) = %q, want %q", tt.args, got, tt.want)
+			}
+		})
+	}
+}
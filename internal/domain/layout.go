@@ -0,0 +1,146 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// DefaultLayout は layout 未指定時の clone 先のディレクトリ構成。
+const DefaultLayout = "{owner}/{repo}"
+
+// RepoRef はリポジトリの host と owner/repo。
+type RepoRef struct {
+	Host  string
+	Owner string
+	Repo  string
+}
+
+// FullName は owner/repo を返す。
+func (r RepoRef) FullName() string {
+	return r.Owner + "/" + r.Repo
+}
+
+var placeholderRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+// Layout は root 配下のリポジトリのパスのテンプレート (例: "{host}/{owner}/{repo}")。
+// ゼロ値は DefaultLayout として振る舞う。
+type Layout struct {
+	template string
+	re       *regexp.Regexp
+	// names は re のグループに対応するプレースホルダ名
+	names []string
+}
+
+// ParseLayout は layout のテンプレートを検証する。空文字列は DefaultLayout。
+// {owner} と {repo} は必須で、{host} は任意。各プレースホルダは1度だけ使える。
+func ParseLayout(template string) (Layout, error) {
+	if template == "" {
+		template = DefaultLayout
+	}
+	if strings.HasPrefix(template, "/") || strings.Contains(template, `\`) || path.Clean(template) != template ||
+		strings.HasPrefix(template, "../") {
+		return Layout{}, fmt.Errorf("layout %q must be a relative path without . or .. segments", template)
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	var names []string
+	last := 0
+	for _, loc := range placeholderRe.FindAllStringIndex(template, -1) {
+		name := template[loc[0]+1 : loc[1]-1]
+		switch name {
+		case "host", "owner", "repo":
+		default:
+			return Layout{}, fmt.Errorf("layout %q: unknown placeholder {%s} (use {host}, {owner} or {repo})", template, name)
+		}
+		for _, n := range names {
+			if n == name {
+				return Layout{}, fmt.Errorf("layout %q: {%s} is used more than once", template, name)
+			}
+		}
+		names = append(names, name)
+		expr.WriteString(regexp.QuoteMeta(template[last:loc[0]]))
+		// owner は最短一致にして "{owner}-{repo}" を最初の区切りで分ける
+		if name == "owner" {
+			expr.WriteString(`([^/]+?)`)
+		} else {
+			expr.WriteString(`([^/]+)`)
+		}
+		last = loc[1]
+	}
+	expr.WriteString(regexp.QuoteMeta(template[last:]))
+	expr.WriteString("$")
+
+	for _, required := range []string{"owner", "repo"} {
+		if !strings.Contains(template, "{"+required+"}") {
+			return Layout{}, fmt.Errorf("layout %q must contain {%s}", template, required)
+		}
+	}
+	return Layout{template: template, re: regexp.MustCompile(expr.String()), names: names}, nil
+}
+
+// orDefault はゼロ値を DefaultLayout に置き換える。
+func (l Layout) orDefault() Layout {
+	if l.template != "" {
+		return l
+	}
+	def, _ := ParseLayout(DefaultLayout)
+	return def
+}
+
+func (l Layout) String() string {
+	return l.orDefault().template
+}
+
+// Depth は root からリポジトリまでのディレクトリの階層数を返す。
+func (l Layout) Depth() int {
+	return strings.Count(l.orDefault().template, "/") + 1
+}
+
+// HasHost はテンプレートに {host} が含まれるかを返す。
+func (l Layout) HasHost() bool {
+	return strings.Contains(l.template, "{host}")
+}
+
+// Path はリポジトリの root からの相対パスを返す。
+func (l Layout) Path(ref RepoRef) string {
+	p := strings.NewReplacer("{host}", ref.Host, "{owner}", ref.Owner, "{repo}", ref.Repo).Replace(l.orDefault().template)
+	return filepath.FromSlash(p)
+}
+
+// Parse は root からの相対パスをテンプレートに当てはめて host と owner/repo を取り出す。
+// {host} を含まないテンプレートでは Host は空になる。
+func (l Layout) Parse(rel string) (RepoRef, bool) {
+	l = l.orDefault()
+	m := l.re.FindStringSubmatch(filepath.ToSlash(rel))
+	if m == nil {
+		return RepoRef{}, false
+	}
+	var ref RepoRef
+	for i, name := range l.names {
+		switch name {
+		case "host":
+			ref.Host = m[i+1]
+		case "owner":
+			ref.Owner = m[i+1]
+		case "repo":
+			ref.Repo = m[i+1]
+		}
+	}
+	return ref, true
+}
+
+// hostPrefix は host だけで決まる先頭のディレクトリ (例: "{host}/{owner}/{repo}" なら "github.com") を返す。
+func (l Layout) hostPrefix(host string) string {
+	var prefix []string
+	for _, seg := range strings.Split(l.orDefault().template, "/") {
+		if strings.Contains(seg, "{owner}") || strings.Contains(seg, "{repo}") {
+			break
+		}
+		prefix = append(prefix, strings.ReplaceAll(seg, "{host}", host))
+	}
+	return filepath.Join(prefix...)
+}
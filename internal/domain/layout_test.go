@@ -0,0 +1,122 @@
+package domain_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestParseLayout_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantMsg  string
+	}{
+		{"ownerがない", "{host}/{repo}", "must contain {owner}"},
+		{"repoがない", "{owner}", "must contain {repo}"},
+		{"未知のプレースホルダ", "{org}/{owner}/{repo}", "unknown placeholder {org}"},
+		{"重複", "{owner}/{owner}-{repo}", "used more than once"},
+		{"絶対パス", "/{owner}/{repo}", "relative path"},
+		{"親ディレクトリ", "../{owner}/{repo}", "relative path"},
+		{"空のセグメント", "{owner}//{repo}", "relative path"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := domain.ParseLayout(tt.template)
+			if err == nil {
+				t.Fatalf("ParseLayout(%q) should fail", tt.template)
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error = %q, want it to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestLayout_PathAndParse(t *testing.T) {
+	ref := domain.RepoRef{Host: "github.com", Owner: "octocat", Repo: "hello-world"}
+	tests := []struct {
+		template  string
+		wantPath  string
+		wantDepth int
+		// wantRef は Parse の結果。{host} がなければ Host は空
+		wantRef domain.RepoRef
+	}{
+		{"", "octocat/hello-world", 2, domain.RepoRef{Owner: "octocat", Repo: "hello-world"}},
+		{"{host}/{owner}/{repo}", "github.com/octocat/hello-world", 3, ref},
+		{"{owner}-{repo}", "octocat-hello-world", 1, domain.RepoRef{Owner: "octocat", Repo: "hello-world"}},
+		{"src/{host}/{owner}/{repo}.git", "src/github.com/octocat/hello-world.git", 4, ref},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			l, err := domain.ParseLayout(tt.template)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := l.Path(ref); got != filepath.FromSlash(tt.wantPath) {
+				t.Errorf("Path() = %q, want %q", got, tt.wantPath)
+			}
+			if got := l.Depth(); got != tt.wantDepth {
+				t.Errorf("Depth() = %d, want %d", got, tt.wantDepth)
+			}
+			got, ok := l.Parse(tt.wantPath)
+			if !ok || got != tt.wantRef {
+				t.Errorf("Parse(%q) = %+v, %v, want %+v", tt.wantPath, got, ok, tt.wantRef)
+			}
+		})
+	}
+}
+
+func TestLayout_ZeroValueIsDefault(t *testing.T) {
+	var l domain.Layout
+	if got := l.String(); got != domain.DefaultLayout {
+		t.Errorf("String() = %q, want %q", got, domain.DefaultLayout)
+	}
+	if _, ok := l.Parse("octocat"); ok {
+		t.Error("Parse should reject a path shallower than the layout")
+	}
+}
+
+func TestProfile_RepoRoot(t *testing.T) {
+	ghq, err := domain.ParseLayout("{host}/{owner}/{repo}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		profile domain.Profile
+		want    string
+	}{
+		{"既定のlayout", domain.Profile{Root: "/repos"}, "/repos"},
+		{"ghq形式はホストのディレクトリ", domain.Profile{Root: "/ghq", Host: "ghe.example.com", Layout: ghq}, "/ghq/ghe.example.com"},
+		{"rootなし", domain.Profile{Layout: ghq}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.RepoRoot(); got != filepath.FromSlash(tt.want) {
+				t.Errorf("RepoRoot() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindByDirectory_SharedRootByHost(t *testing.T) {
+	ghq, err := domain.ParseLayout("{host}/{owner}/{repo}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	profiles := []domain.Profile{
+		{Name: "personal", GHConfigDir: "/config/personal", Root: "/ghq", Layout: ghq},
+		{Name: "work", GHConfigDir: "/config/work", Root: "/ghq", Host: "ghe.example.com", Layout: ghq},
+	}
+
+	p, err := domain.FindByDirectory(profiles, "/ghq/ghe.example.com/corp/api")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "work" {
+		t.Errorf("Name = %q, want %q", p.Name, "work")
+	}
+}
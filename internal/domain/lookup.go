@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ProfileNotFoundError は名前に一致するプロファイルが無いことを表す。
+type ProfileNotFoundError struct {
+	Name string
+	// Suggestions は Name に近いプロファイル名 (近い順)
+	Suggestions []string
+	// Available はすべてのプロファイル名
+	Available []string
+}
+
+func (e *ProfileNotFoundError) Error() string {
+	msg := fmt.Sprintf("profile %q not found", e.Name)
+	if len(e.Suggestions) > 0 {
+		quoted := make([]string, len(e.Suggestions))
+		for i, s := range e.Suggestions {
+			quoted[i] = fmt.Sprintf("%q", s)
+		}
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(quoted, " or "))
+	}
+	if len(e.Available) > 0 {
+		msg += "; available: " + strings.Join(e.Available, ", ")
+	}
+	return msg
+}
+
+func (e *ProfileNotFoundError) Unwrap() error {
+	return ErrProfileNotFound
+}
+
+// AmbiguousProfileError は前方一致するプロファイルが複数あることを表す。
+type AmbiguousProfileError struct {
+	Name    string
+	Matches []string
+}
+
+func (e *AmbiguousProfileError) Error() string {
+	return fmt.Sprintf("profile %q is ambiguous: matches %s", e.Name, strings.Join(e.Matches, ", "))
+}
+
+// FindProfile は名前、別名 (aliases)、名前か別名への一意な前方一致の順でプロファイルを探す。
+// 見つからなければ *ProfileNotFoundError、前方一致が複数なら *AmbiguousProfileError を返す。
+func FindProfile(profiles []Profile, name string) (Profile, error) {
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	for _, p := range profiles {
+		if slices.Contains(p.Aliases, name) {
+			return p, nil
+		}
+	}
+
+	var matches []string
+	found := -1
+	for i, p := range profiles {
+		if strings.HasPrefix(p.Name, name) || slices.ContainsFunc(p.Aliases, func(a string) bool { return strings.HasPrefix(a, name) }) {
+			matches = append(matches, p.Name)
+			found = i
+		}
+	}
+	switch {
+	case name != "" && len(matches) == 1:
+		return profiles[found], nil
+	case name != "" && len(matches) > 1:
+		return Profile{}, &AmbiguousProfileError{Name: name, Matches: matches}
+	}
+
+	var candidates []string
+	available := make([]string, len(profiles))
+	for i, p := range profiles {
+		available[i] = p.Name
+		candidates = append(candidates, p.Name)
+		candidates = append(candidates, p.Aliases...)
+	}
+	// 別名が近い場合はプロファイル名で示す
+	var suggestions []string
+	for _, s := range Suggest(name, candidates) {
+		for _, p := range profiles {
+			if (p.Name == s || slices.Contains(p.Aliases, s)) && !slices.Contains(suggestions, p.Name) {
+				suggestions = append(suggestions, p.Name)
+			}
+		}
+	}
+	return Profile{}, &ProfileNotFoundError{Name: name, Suggestions: suggestions, Available: available}
+}
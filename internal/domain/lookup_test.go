@@ -0,0 +1,82 @@
+package domain_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestFindProfile(t *testing.T) {
+	profiles := []domain.Profile{
+		{Name: "work", Aliases: []string{"w", "corp"}},
+		{Name: "personal", Aliases: []string{"me"}},
+		{Name: "playground"},
+	}
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "名前", input: "work", want: "work"},
+		{name: "別名", input: "corp", want: "work"},
+		{name: "名前の一意な前方一致", input: "pe", want: "personal"},
+		{name: "別名の一意な前方一致", input: "co", want: "work"},
+		{name: "名前の完全一致は別名の前方一致より優先", input: "w", want: "work"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := domain.FindProfile(profiles, tt.input)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("FindProfile(%q) = %q, want %q", tt.input, got.Name, tt.want)
+			}
+		})
+	}
+}
+
+func TestFindProfile_Errors(t *testing.T) {
+	profiles := []domain.Profile{
+		{Name: "work", Aliases: []string{"corp"}},
+		{Name: "personal"},
+		{Name: "playground"},
+	}
+
+	t.Run("前方一致が複数", func(t *testing.T) {
+		_, err := domain.FindProfile(profiles, "p")
+		var ambiguous *domain.AmbiguousProfileError
+		if !errors.As(err, &ambiguous) {
+			t.Fatalf("err = %v, want *AmbiguousProfileError", err)
+		}
+		if want := `profile "p" is ambiguous: matches personal, playground`; err.Error() != want {
+			t.Errorf("err = %q, want %q", err, want)
+		}
+	})
+
+	t.Run("候補と一覧を示す", func(t *testing.T) {
+		_, err := domain.FindProfile(profiles, "wrok")
+		var notFound *domain.ProfileNotFoundError
+		if !errors.As(err, &notFound) || !errors.Is(err, domain.ErrProfileNotFound) {
+			t.Fatalf("err = %v, want *ProfileNotFoundError", err)
+		}
+		if want := `profile "wrok" not found (did you mean "work"?); available: work, personal, playground`; err.Error() != want {
+			t.Errorf("err = %q, want %q", err, want)
+		}
+	})
+
+	t.Run("別名に近い場合はプロファイル名を示す", func(t *testing.T) {
+		_, err := domain.FindProfile(profiles, "crop")
+		var notFound *domain.ProfileNotFoundError
+		if !errors.As(err, &notFound) || len(notFound.Suggestions) != 1 || notFound.Suggestions[0] != "work" {
+			t.Errorf("err = %v, want suggestion work", err)
+		}
+	})
+
+	t.Run("空の名前は前方一致しない", func(t *testing.T) {
+		if _, err := domain.FindProfile(profiles, ""); !errors.Is(err, domain.ErrProfileNotFound) {
+			t.Errorf("err = %v, want %v", err, domain.ErrProfileNotFound)
+		}
+	})
+}
@@ -0,0 +1,10 @@
+package domain
+
+// MarkerFileName はディレクトリにプロファイルを固定するマーカーファイルの名前。
+const MarkerFileName = ".gh-mrepo.toml"
+
+// Marker はリポジトリまたは親ディレクトリに置かれたマーカーファイルを表す。
+type Marker struct {
+	Path    string // マーカーファイルのパス
+	Profile string // 固定するプロファイル名
+}
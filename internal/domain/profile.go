@@ -2,17 +2,30 @@ package domain
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
+// DefaultHost は host 未指定時に使用する GitHub ホスト名。
+const DefaultHost = "github.com"
+
 // Profile はGitHubアカウントの設定プロファイルを表す値オブジェクト。
 type Profile struct {
-	Name           string // TOMLセクション名
-	GHConfigDir    string // 展開済み絶対パス
-	Root           string // clone先ルート (空の場合はデフォルト動作)
-	GitConfigName  string // git config user.name (空の場合は変更しない)
-	GitConfigEmail string // git config user.email (空の場合は変更しない)
-	SSHIdentity    string // SSH秘密鍵パス (空の場合は未設定)
+	Name           string              // TOMLセクション名
+	GHConfigDir    string              // 展開済み絶対パス
+	Root           string              // clone先ルート (空の場合はデフォルト動作)
+	GitConfigName  string              // git config user.name (空の場合は変更しない)
+	GitConfigEmail string              // git config user.email (空の場合は変更しない)
+	SSHIdentity    string              // SSH秘密鍵パス (空の場合は未設定)
+	Host           string              // GitHubホスト名 (空の場合は github.com)
+	User           string              // 使用する gh アカウント (空の場合は hosts.yml のアクティブユーザー)
+	Tags           []string            // --tag で絞り込むためのタグ
+	Aliases        []string            // --user などで名前の代わりに使える別名
+	Env            map[string]string   // gh やコマンドの実行時に追加する環境変数
+	Defaults       map[string][]string // サブコマンド ("repo list" など) ごとの既定の引数
+	Layout         Layout              // root 配下のリポジトリのパスのテンプレート
+	ScanDepth      int                 // root 配下でリポジトリを探す最大の階層数 (0 の場合は layout の階層数)
+	Exclude        []string            // root 配下でたどらないディレクトリの glob パターン
 }
 
 func NewProfile(name, ghConfigDir, root string) (Profile, error) {
@@ -29,12 +42,104 @@ func NewProfile(name, ghConfigDir, root string) (Profile, error) {
 	}, nil
 }
 
-// FindByDirectory は指定ディレクトリに一致するプロファイルを返す。
+// HostName は Host を返す。未設定の場合は DefaultHost を返す。
+func (p Profile) HostName() string {
+	if p.Host == "" {
+		return DefaultHost
+	}
+	return p.Host
+}
+
+// RepoRoot はこのプロファイルのリポジトリが置かれるディレクトリを返す。
+// layout が "{host}/{owner}/{repo}" なら root/<host> になり、ホストの異なるプロファイルで root を共有できる。
+func (p Profile) RepoRoot() string {
+	if p.Root == "" {
+		return ""
+	}
+	return filepath.Join(p.Root, p.Layout.hostPrefix(p.HostName()))
+}
+
+// RepoPath はリポジトリの clone 先のパスを返す。
+func (p Profile) RepoPath(ref RepoRef) string {
+	return filepath.Join(p.Root, p.Layout.Path(ref))
+}
+
+// GitSetting はリポジトリローカルの git config の値。Value が空なら設定を消す。
+type GitSetting struct {
+	Key   string
+	Value string
+}
+
+// GitSettings は switch でリポジトリに書き込む git config を返す。
+// user.name と user.email は設定されている場合だけ含め、core.sshCommand は ssh_identity がなければ消す。
+func (p Profile) GitSettings() []GitSetting {
+	var settings []GitSetting
+	if p.GitConfigName != "" {
+		settings = append(settings, GitSetting{Key: "user.name", Value: p.GitConfigName})
+	}
+	if p.GitConfigEmail != "" {
+		settings = append(settings, GitSetting{Key: "user.email", Value: p.GitConfigEmail})
+	}
+	return append(settings, GitSetting{Key: "core.sshCommand", Value: p.SSHCommand()})
+}
+
+// SSHCommand は ssh_identity を使う ssh コマンドを返す。未設定の場合は空文字列を返す。
+func (p Profile) SSHCommand() string {
+	if p.SSHIdentity == "" {
+		return ""
+	}
+	return fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", p.SSHIdentity)
+}
+
+// AuthLoginCommand は再認証用の gh auth login コマンドを返す。
+func (p Profile) AuthLoginCommand() string {
+	cmd := fmt.Sprintf("GH_CONFIG_DIR=%s gh auth login", p.GHConfigDir)
+	if p.HostName() != DefaultHost {
+		cmd += " --hostname " + p.HostName()
+	}
+	return cmd
+}
+
+// FindByDirectory は dir を配下に含む root を持つプロファイルのうち、最も深い root のものを返す。
+// パスは Clean とシンボリックリンク解決をした上で、パス要素の境界で比較する。
 func FindByDirectory(profiles []Profile, dir string) (Profile, error) {
-	for _, p := range profiles {
-		if p.Root != "" && strings.HasPrefix(dir, p.Root) {
-			return p, nil
+	target := canonicalPath(dir)
+
+	best := -1
+	bestLen := -1
+	for i, p := range profiles {
+		if p.Root == "" {
+			continue
+		}
+		root := canonicalPath(p.RepoRoot())
+		if !isWithin(root, target) {
+			continue
+		}
+		if len(root) > bestLen {
+			best = i
+			bestLen = len(root)
 		}
 	}
-	return Profile{}, fmt.Errorf("no profile found for directory %q", dir)
+	if best < 0 {
+		return Profile{}, fmt.Errorf("no profile found for directory %q", dir)
+	}
+	return profiles[best], nil
+}
+
+// canonicalPath はパスを Clean し、可能であればシンボリックリンクを解決する。
+func canonicalPath(path string) string {
+	cleaned := filepath.Clean(path)
+	if resolved, err := filepath.EvalSymlinks(cleaned); err == nil {
+		return resolved
+	}
+	return cleaned
+}
+
+// isWithin は path が root 自身またはその配下であるかを返す。
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
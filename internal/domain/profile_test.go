@@ -1,6 +1,9 @@
 package domain_test
 
 import (
+	"os"
+	"path/filepath"
+	"slices"
 	"testing"
 
 	"github.com/sarrrrry/gh-mrepo/internal/domain"
@@ -90,6 +93,19 @@ func TestProfile_SSHIdentityFieldEmpty(t *testing.T) {
 	}
 }
 
+func TestProfile_SSHCommand(t *testing.T) {
+	p := domain.Profile{Name: "work", GHConfigDir: "/config/work", SSHIdentity: "/home/user/.ssh/id_work"}
+	want := "ssh -i /home/user/.ssh/id_work -o IdentitiesOnly=yes"
+	if got := p.SSHCommand(); got != want {
+		t.Errorf("SSHCommand() = %q, want %q", got, want)
+	}
+
+	p.SSHIdentity = ""
+	if got := p.SSHCommand(); got != "" {
+		t.Errorf("SSHCommand() = %q, want empty", got)
+	}
+}
+
 func TestProfile_GitConfigFieldsEmpty(t *testing.T) {
 	p, err := domain.NewProfile("personal", "/home/user/.config/gh", "")
 	if err != nil {
@@ -103,6 +119,39 @@ func TestProfile_GitConfigFieldsEmpty(t *testing.T) {
 	}
 }
 
+func TestProfile_GitSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile domain.Profile
+		want    []domain.GitSetting
+	}{
+		{
+			name:    "すべて設定",
+			profile: domain.Profile{GitConfigName: "Work", GitConfigEmail: "work@example.com", SSHIdentity: "~/.ssh/id_work"},
+			want: []domain.GitSetting{
+				{Key: "user.name", Value: "Work"},
+				{Key: "user.email", Value: "work@example.com"},
+				{Key: "core.sshCommand", Value: "ssh -i ~/.ssh/id_work -o IdentitiesOnly=yes"},
+			},
+		},
+		{
+			name:    "ssh_identity がなければ core.sshCommand を消す",
+			profile: domain.Profile{GitConfigEmail: "work@example.com"},
+			want: []domain.GitSetting{
+				{Key: "user.email", Value: "work@example.com"},
+				{Key: "core.sshCommand", Value: ""},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.GitSettings(); !slices.Equal(got, tt.want) {
+				t.Errorf("GitSettings() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestFindByDirectory_Match(t *testing.T) {
 	profiles := []domain.Profile{
 		{Name: "personal", GHConfigDir: "/config/personal", Root: "/home/user/personal"},
@@ -118,7 +167,7 @@ func TestFindByDirectory_Match(t *testing.T) {
 	}
 }
 
-func TestFindByDirectory_FirstMatch(t *testing.T) {
+func TestFindByDirectory_LongestMatch(t *testing.T) {
 	profiles := []domain.Profile{
 		{Name: "parent", GHConfigDir: "/config/parent", Root: "/home/user"},
 		{Name: "child", GHConfigDir: "/config/child", Root: "/home/user/child"},
@@ -128,8 +177,66 @@ func TestFindByDirectory_FirstMatch(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
+	if p.Name != "child" {
+		t.Errorf("Name = %q, want %q (longest match wins)", p.Name, "child")
+	}
+
+	p, err = domain.FindByDirectory(profiles, "/home/user/other")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 	if p.Name != "parent" {
-		t.Errorf("Name = %q, want %q (first match wins)", p.Name, "parent")
+		t.Errorf("Name = %q, want %q", p.Name, "parent")
+	}
+}
+
+func TestFindByDirectory_PathComponentBoundary(t *testing.T) {
+	profiles := []domain.Profile{
+		{Name: "work", GHConfigDir: "/config/work", Root: "/home/user/repos/work"},
+	}
+
+	if _, err := domain.FindByDirectory(profiles, "/home/user/repos/work-old/repo"); err == nil {
+		t.Error("work-old should not match root work")
+	}
+
+	tests := []string{
+		"/home/user/repos/work",
+		"/home/user/repos/work/",
+		"/home/user/repos/work/./acme/../acme/api",
+	}
+	for _, dir := range tests {
+		p, err := domain.FindByDirectory(profiles, dir)
+		if err != nil {
+			t.Errorf("FindByDirectory(%q): unexpected error: %v", dir, err)
+			continue
+		}
+		if p.Name != "work" {
+			t.Errorf("FindByDirectory(%q) = %q, want %q", dir, p.Name, "work")
+		}
+	}
+}
+
+func TestFindByDirectory_Symlink(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "repos", "work")
+	if err := os.MkdirAll(filepath.Join(root, "acme", "api"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(base, "work-link")
+	if err := os.Symlink(root, link); err != nil {
+		t.Skipf("symlink not supported: %v", err)
+	}
+
+	profiles := []domain.Profile{
+		{Name: "work", GHConfigDir: "/config/work", Root: root},
+	}
+
+	p, err := domain.FindByDirectory(profiles, filepath.Join(link, "acme", "api"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if p.Name != "work" {
+		t.Errorf("Name = %q, want %q", p.Name, "work")
 	}
 }
 
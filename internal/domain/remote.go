@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Remote は git リモート URL を分解した値オブジェクト。
+type Remote struct {
+	URL      string
+	Protocol string // "https" または "ssh"
+	Host     string
+	Owner    string
+	Repo     string
+}
+
+// FullName は owner/repo を返す。
+func (r Remote) FullName() string {
+	return r.Owner + "/" + r.Repo
+}
+
+// ParseRemote は HTTPS URL、ssh:// URL、scp 形式 (git@host:owner/repo.git) のリモート URL を分解する。
+func ParseRemote(raw string) (Remote, error) {
+	r := Remote{URL: raw}
+	var path string
+
+	if u, err := url.Parse(raw); err == nil && u.Scheme != "" && u.Host != "" {
+		switch u.Scheme {
+		case "https", "http":
+			r.Protocol = "https"
+		case "ssh", "git+ssh":
+			r.Protocol = "ssh"
+		default:
+			return Remote{}, fmt.Errorf("unsupported remote URL scheme %q", u.Scheme)
+		}
+		r.Host = u.Hostname()
+		path = u.Path
+	} else if at := strings.Index(raw, "@"); at >= 0 && strings.Contains(raw[at:], ":") {
+		// scp形式: git@github.com:owner/repo.git
+		hostPath := raw[at+1:]
+		colon := strings.Index(hostPath, ":")
+		r.Protocol = "ssh"
+		r.Host = hostPath[:colon]
+		path = hostPath[colon+1:]
+	} else {
+		return Remote{}, fmt.Errorf("unrecognized remote URL %q", raw)
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return Remote{}, fmt.Errorf("remote URL %q has no owner/repo", raw)
+	}
+	r.Owner = parts[len(parts)-2]
+	r.Repo = strings.TrimSuffix(parts[len(parts)-1], ".git")
+	return r, nil
+}
@@ -0,0 +1,78 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestParseRemote(t *testing.T) {
+	tests := []struct {
+		name         string
+		raw          string
+		wantProtocol string
+		wantHost     string
+		wantFullName string
+	}{
+		{
+			name:         "HTTPS URL",
+			raw:          "https://github.com/sarrrrry/gh-mrepo.git",
+			wantProtocol: "https",
+			wantHost:     "github.com",
+			wantFullName: "sarrrrry/gh-mrepo",
+		},
+		{
+			name:         "HTTPS URL (.gitなし)",
+			raw:          "https://ghe.example.com/acme/api",
+			wantProtocol: "https",
+			wantHost:     "ghe.example.com",
+			wantFullName: "acme/api",
+		},
+		{
+			name:         "scp形式",
+			raw:          "git@github.com:sarrrrry/gh-mrepo.git",
+			wantProtocol: "ssh",
+			wantHost:     "github.com",
+			wantFullName: "sarrrrry/gh-mrepo",
+		},
+		{
+			name:         "ssh:// URL (ポート付き)",
+			raw:          "ssh://git@ghe.example.com:2222/acme/api.git",
+			wantProtocol: "ssh",
+			wantHost:     "ghe.example.com",
+			wantFullName: "acme/api",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := domain.ParseRemote(tt.raw)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if r.Protocol != tt.wantProtocol {
+				t.Errorf("Protocol = %q, want %q", r.Protocol, tt.wantProtocol)
+			}
+			if r.Host != tt.wantHost {
+				t.Errorf("Host = %q, want %q", r.Host, tt.wantHost)
+			}
+			if r.FullName() != tt.wantFullName {
+				t.Errorf("FullName() = %q, want %q", r.FullName(), tt.wantFullName)
+			}
+		})
+	}
+}
+
+func TestParseRemote_Invalid(t *testing.T) {
+	tests := []string{
+		"",
+		"/local/path/repo",
+		"https://github.com/only-owner",
+		"file:///tmp/repo.git",
+	}
+	for _, raw := range tests {
+		if _, err := domain.ParseRemote(raw); err == nil {
+			t.Errorf("ParseRemote(%q): expected error, got nil", raw)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// ScanOptions は root 配下のリポジトリの探し方。
+type ScanOptions struct {
+	Layout Layout
+	// Depth は root からたどる最大の階層数
+	Depth int
+	// Exclude はたどらないディレクトリの glob パターン
+	Exclude []string
+}
+
+// RepoDir は root 配下で見つかったリポジトリ。
+type RepoDir struct {
+	RepoRef
+	// Path はリポジトリの絶対パス
+	Path string
+	// Bare は作業ツリーのない bare リポジトリかを表す
+	Bare bool
+	// Origin は remote.origin.url を分解したもの。origin がなければゼロ値、分解できなければ URL だけ持つ
+	Origin Remote
+}
+
+// Actual は origin があれば origin の host と owner/repo を、なければパスから読み取った値を返す。
+func (d RepoDir) Actual() RepoRef {
+	if d.Origin.Owner == "" {
+		return d.RepoRef
+	}
+	return RepoRef{Host: d.Origin.Host, Owner: d.Origin.Owner, Repo: d.Origin.Repo}
+}
+
+// Mismatch は置き場所の host と owner/repo が origin と食い違うかを返す。
+// リポジトリの名前の変更や移管のあとに起きる。Host が空なら host は比べない。
+// lls では layout に {host} がなくてもプロファイルのホストを Host に入れるので、常に host も比べる。
+func (d RepoDir) Mismatch() bool {
+	if d.Origin.Owner == "" {
+		return false
+	}
+	return !strings.EqualFold(d.Owner, d.Origin.Owner) || !strings.EqualFold(d.Repo, d.Origin.Repo) ||
+		(d.Host != "" && !strings.EqualFold(d.Host, d.Origin.Host))
+}
+
+// ScanOptions は root 配下を探すときの設定を返す。scan_depth 未設定なら layout の階層数までたどる。
+func (p Profile) ScanOptions() ScanOptions {
+	depth := p.ScanDepth
+	if depth == 0 {
+		depth = p.Layout.Depth()
+	}
+	return ScanOptions{Layout: p.Layout, Depth: depth, Exclude: p.Exclude}
+}
+
+// ValidateExclude は exclude の glob パターンの構文を検証する。
+func ValidateExclude(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Excluded は root からの相対パス rel のディレクトリを除外するかを返す。
+// "/" を含むパターンは相対パス全体と、含まないパターンはディレクトリ名と照合する。
+func (o ScanOptions) Excluded(rel string) bool {
+	for _, pattern := range o.Exclude {
+		target := path.Base(rel)
+		if strings.Contains(pattern, "/") {
+			target = rel
+		}
+		if ok, _ := path.Match(pattern, target); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Ref は root からの相対パス rel のリポジトリの host と owner/repo を返す。
+// layout に一致しなければ、親ディレクトリ名を owner、ディレクトリ名を repo とみなす。
+// bare リポジトリの名前の末尾の ".git" は除く。
+func (o ScanOptions) Ref(rel string, bare bool) (RepoRef, bool) {
+	ref, ok := o.Layout.Parse(rel)
+	if !ok {
+		segments := strings.Split(rel, "/")
+		if len(segments) < 2 {
+			return RepoRef{}, false
+		}
+		ref = RepoRef{Owner: segments[len(segments)-2], Repo: segments[len(segments)-1]}
+	}
+	if bare {
+		ref.Repo = strings.TrimSuffix(ref.Repo, ".git")
+	}
+	return ref, true
+}
@@ -0,0 +1,139 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestProfile_ScanOptions_Depth(t *testing.T) {
+	ghq, err := domain.ParseLayout("{host}/{owner}/{repo}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		profile domain.Profile
+		want    int
+	}{
+		{"既定はlayoutの階層数", domain.Profile{}, 2},
+		{"ghq形式", domain.Profile{Layout: ghq}, 3},
+		{"scan_depthを優先", domain.Profile{Layout: ghq, ScanDepth: 5}, 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.profile.ScanOptions().Depth; got != tt.want {
+				t.Errorf("Depth = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanOptions_Excluded(t *testing.T) {
+	opts := domain.ScanOptions{Exclude: []string{"archive", "owner/tmp-*"}}
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"archive", true},
+		{"owner/archive", true},
+		{"owner/tmp-1", true},
+		{"other/tmp-1", false},
+		{"owner/keep", false},
+	}
+	for _, tt := range tests {
+		if got := opts.Excluded(tt.rel); got != tt.want {
+			t.Errorf("Excluded(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+}
+
+func TestScanOptions_Ref(t *testing.T) {
+	opts := domain.Profile{}.ScanOptions()
+	tests := []struct {
+		name   string
+		rel    string
+		bare   bool
+		want   domain.RepoRef
+		wantOK bool
+	}{
+		{"layoutに一致", "octocat/hello", false, domain.RepoRef{Owner: "octocat", Repo: "hello"}, true},
+		{"深い階層は親ディレクトリをownerにする", "group/octocat/hello", false, domain.RepoRef{Owner: "octocat", Repo: "hello"}, true},
+		{"bareは.gitを除く", "octocat/hello.git", true, domain.RepoRef{Owner: "octocat", Repo: "hello"}, true},
+		{"ownerのない階層", "hello", false, domain.RepoRef{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := opts.Ref(tt.rel, tt.bare)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Ref(%q) = %+v, %v, want %+v, %v", tt.rel, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateExclude(t *testing.T) {
+	if err := domain.ValidateExclude([]string{"archive", "*/tmp-*"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := domain.ValidateExclude([]string{"[unclosed"}); err == nil {
+		t.Error("ValidateExclude should reject a malformed pattern")
+	}
+}
+
+func TestRepoDir_Mismatch(t *testing.T) {
+	origin := func(raw string) domain.Remote {
+		r, err := domain.ParseRemote(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	tests := []struct {
+		name       string
+		dir        domain.RepoDir
+		want       bool
+		wantActual domain.RepoRef
+	}{
+		{
+			name:       "originなし",
+			dir:        domain.RepoDir{RepoRef: domain.RepoRef{Owner: "octocat", Repo: "hello"}},
+			want:       false,
+			wantActual: domain.RepoRef{Owner: "octocat", Repo: "hello"},
+		},
+		{
+			name:       "一致 (大文字小文字は区別しない)",
+			dir:        domain.RepoDir{RepoRef: domain.RepoRef{Host: "github.com", Owner: "octocat", Repo: "hello"}, Origin: origin("git@github.com:OctoCat/Hello.git")},
+			want:       false,
+			wantActual: domain.RepoRef{Host: "github.com", Owner: "OctoCat", Repo: "Hello"},
+		},
+		{
+			name:       "名前の変更",
+			dir:        domain.RepoDir{RepoRef: domain.RepoRef{Owner: "octocat", Repo: "old"}, Origin: origin("https://github.com/octocat/new.git")},
+			want:       true,
+			wantActual: domain.RepoRef{Host: "github.com", Owner: "octocat", Repo: "new"},
+		},
+		{
+			name:       "ホストの違い",
+			dir:        domain.RepoDir{RepoRef: domain.RepoRef{Host: "github.com", Owner: "octocat", Repo: "hello"}, Origin: origin("git@ghe.example.com:octocat/hello.git")},
+			want:       true,
+			wantActual: domain.RepoRef{Host: "ghe.example.com", Owner: "octocat", Repo: "hello"},
+		},
+		{
+			name:       "分解できないorigin",
+			dir:        domain.RepoDir{RepoRef: domain.RepoRef{Owner: "octocat", Repo: "hello"}, Origin: domain.Remote{URL: "/srv/git/hello.git"}},
+			want:       false,
+			wantActual: domain.RepoRef{Owner: "octocat", Repo: "hello"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dir.Mismatch(); got != tt.want {
+				t.Errorf("Mismatch() = %v, want %v", got, tt.want)
+			}
+			if got := tt.dir.Actual(); got != tt.wantActual {
+				t.Errorf("Actual() = %+v, want %+v", got, tt.wantActual)
+			}
+		})
+	}
+}
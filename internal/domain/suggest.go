@@ -0,0 +1,55 @@
+package domain
+
+import "sort"
+
+// Suggest は candidates のうち input と綴りが近いものを、近い順に返す。
+// タイプミスの指摘 ("did you mean ...") に使う。
+func Suggest(input string, candidates []string) []string {
+	limit := max(1, len(input)/3)
+	type scored struct {
+		name string
+		dist int
+	}
+	var found []scored
+	for _, c := range candidates {
+		if c == input {
+			continue
+		}
+		if d := editDistance(input, c); d <= limit {
+			found = append(found, scored{c, d})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].dist < found[j].dist })
+
+	names := make([]string, len(found))
+	for i, s := range found {
+		names[i] = s.name
+	}
+	return names
+}
+
+// editDistance は a と b の編集距離 (隣接文字の入れ替えを1操作とする) を返す。
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,32 @@
+package domain_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestSuggest(t *testing.T) {
+	keys := []string{"gh_config_dir", "root", "git_config_name", "git_config_email", "ssh_identity", "host", "user"}
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{input: "gh_confg_dir", want: []string{"gh_config_dir"}},
+		{input: "ssh_identiy", want: []string{"ssh_identity"}},
+		{input: "hsot", want: []string{"host"}},
+		{input: "usr", want: []string{"user"}},
+		{input: "git_config_nmae", want: []string{"git_config_name", "git_config_email"}},
+		{input: "completely_different", want: nil},
+		{input: "root", want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got := domain.Suggest(tt.input, keys)
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Suggest(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// HasTag はプロファイルが tag を持つかを返す。
+func (p Profile) HasTag(tag string) bool {
+	return slices.Contains(p.Tags, tag)
+}
+
+// TagFilter は tags によるプロファイルの絞り込み条件。ゼロ値はすべてのプロファイルに一致する。
+type TagFilter struct {
+	// Include が空でなければ、いずれかのタグを持つプロファイルだけを残す
+	Include []string
+	// Exclude のいずれかのタグを持つプロファイルは除く
+	Exclude []string
+}
+
+// IsZero は条件が指定されていないかを返す。
+func (f TagFilter) IsZero() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// Match はプロファイルが条件に一致するかを返す。
+func (f TagFilter) Match(p Profile) bool {
+	if slices.ContainsFunc(f.Exclude, p.HasTag) {
+		return false
+	}
+	return len(f.Include) == 0 || slices.ContainsFunc(f.Include, p.HasTag)
+}
+
+// Apply は条件に一致するプロファイルを返す。
+// どのプロファイルにも付いていないタグを指定した場合や、一致するものが無い場合はエラーを返す。
+func (f TagFilter) Apply(profiles []Profile) ([]Profile, error) {
+	if f.IsZero() {
+		return profiles, nil
+	}
+	known := Tags(profiles)
+	for _, tag := range slices.Concat(f.Include, f.Exclude) {
+		if slices.Contains(known, tag) {
+			continue
+		}
+		if s := Suggest(tag, known); len(s) > 0 {
+			return nil, fmt.Errorf("%w %q (did you mean %q?)", ErrUnknownTag, tag, s[0])
+		}
+		return nil, fmt.Errorf("%w %q (available: %s)", ErrUnknownTag, tag, strings.Join(known, ", "))
+	}
+
+	var matched []Profile
+	for _, p := range profiles {
+		if f.Match(p) {
+			matched = append(matched, p)
+		}
+	}
+	if len(matched) == 0 {
+		return nil, ErrNoMatchingProfiles
+	}
+	return matched, nil
+}
+
+// Tags はプロファイルに付いているタグを重複なく名前順に返す。
+func Tags(profiles []Profile) []string {
+	var tags []string
+	for _, p := range profiles {
+		for _, tag := range p.Tags {
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// TagGroup は同じタグを持つプロファイルのまとまり。
+type TagGroup struct {
+	// Tag が空のグループはタグの無いプロファイル
+	Tag      string
+	Profiles []Profile
+}
+
+// GroupByTag はプロファイルをタグごとにまとめる。グループはタグの名前順で、タグの無いプロファイルは最後。
+// 複数のタグを持つプロファイルはそれぞれのグループに入る。
+func GroupByTag(profiles []Profile) []TagGroup {
+	var groups []TagGroup
+	for _, tag := range Tags(profiles) {
+		g := TagGroup{Tag: tag}
+		for _, p := range profiles {
+			if p.HasTag(tag) {
+				g.Profiles = append(g.Profiles, p)
+			}
+		}
+		groups = append(groups, g)
+	}
+	untagged := TagGroup{}
+	for _, p := range profiles {
+		if len(p.Tags) == 0 {
+			untagged.Profiles = append(untagged.Profiles, p)
+		}
+	}
+	if len(untagged.Profiles) > 0 {
+		groups = append(groups, untagged)
+	}
+	return groups
+}
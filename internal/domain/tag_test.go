@@ -0,0 +1,93 @@
+package domain_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func taggedProfiles() []domain.Profile {
+	return []domain.Profile{
+		{Name: "work", Tags: []string{"work"}},
+		{Name: "oss", Tags: []string{"oss", "personal"}},
+		{Name: "personal", Tags: []string{"personal"}},
+		{Name: "misc"},
+	}
+}
+
+func names(profiles []domain.Profile) string {
+	s := make([]string, len(profiles))
+	for i, p := range profiles {
+		s[i] = p.Name
+	}
+	return strings.Join(s, ",")
+}
+
+func TestTagFilter_Apply(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  domain.TagFilter
+		want    string
+		wantErr error
+		wantMsg string
+	}{
+		{name: "指定なしはすべて", filter: domain.TagFilter{}, want: "work,oss,personal,misc"},
+		{name: "tag", filter: domain.TagFilter{Include: []string{"personal"}}, want: "oss,personal"},
+		{name: "tag を複数指定するといずれか", filter: domain.TagFilter{Include: []string{"work", "oss"}}, want: "work,oss"},
+		{name: "exclude-tag", filter: domain.TagFilter{Exclude: []string{"personal"}}, want: "work,misc"},
+		{
+			name:   "tag と exclude-tag",
+			filter: domain.TagFilter{Include: []string{"personal"}, Exclude: []string{"oss"}},
+			want:   "personal",
+		},
+		{
+			name:    "未知のタグは候補を示す",
+			filter:  domain.TagFilter{Include: []string{"wrok"}},
+			wantErr: domain.ErrUnknownTag,
+			wantMsg: `did you mean "work"?`,
+		},
+		{
+			name:    "未知のタグで候補が無ければ一覧を示す",
+			filter:  domain.TagFilter{Exclude: []string{"zzz"}},
+			wantErr: domain.ErrUnknownTag,
+			wantMsg: "available: oss, personal, work",
+		},
+		{
+			name:    "一致なし",
+			filter:  domain.TagFilter{Include: []string{"work"}, Exclude: []string{"work"}},
+			wantErr: domain.ErrNoMatchingProfiles,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.filter.Apply(taggedProfiles())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || !strings.Contains(err.Error(), tt.wantMsg) {
+					t.Fatalf("err = %v, want %v containing %q", err, tt.wantErr, tt.wantMsg)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if names(got) != tt.want {
+				t.Errorf("Apply = %s, want %s", names(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestGroupByTag(t *testing.T) {
+	groups := domain.GroupByTag(taggedProfiles())
+
+	var got []string
+	for _, g := range groups {
+		got = append(got, g.Tag+"="+names(g.Profiles))
+	}
+	want := "oss=oss personal=oss,personal work=work =misc"
+	if strings.Join(got, " ") != want {
+		t.Errorf("GroupByTag = %v, want %s", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "time"
+
+// WorkStatus はローカルリポジトリの作業ツリーの状態。
+type WorkStatus struct {
+	// Branch は現在のブランチ。detached HEAD なら空
+	Branch string `json:"branch"`
+	// Upstream は追跡しているブランチ (例: "origin/main")。なければ空
+	Upstream string `json:"upstream,omitempty"`
+	// Dirty は変更のあるファイル (ステージ済みを含む) の数
+	Dirty     int `json:"dirty"`
+	Untracked int `json:"untracked"`
+	Ahead     int `json:"ahead"`
+	Behind    int `json:"behind"`
+	Stashes   int `json:"stashes"`
+	// LastCommit は HEAD のコミット日時。コミットがなければゼロ値
+	LastCommit time.Time `json:"last_commit,omitzero"`
+}
+
+// IsDirty は未コミットの変更か未追跡のファイルがあるかを返す。
+func (s WorkStatus) IsDirty() bool {
+	return s.Dirty > 0 || s.Untracked > 0
+}
+
+// HasUnpushed は push していないコミットがあるかを返す。
+// 追跡ブランチのないブランチにコミットがあれば、それも push していないとみなす。
+func (s WorkStatus) HasUnpushed() bool {
+	if s.Upstream == "" {
+		return s.Branch != "" && !s.LastCommit.IsZero()
+	}
+	return s.Ahead > 0
+}
+
+// StatusFilter は lls の --dirty と --ahead による絞り込み。
+type StatusFilter struct {
+	Dirty bool
+	Ahead bool
+}
+
+// IsZero は絞り込みの条件がないかを返す。
+func (f StatusFilter) IsZero() bool {
+	return !f.Dirty && !f.Ahead
+}
+
+// Match は status が条件のいずれかに当てはまるかを返す。条件がなければ常に true。
+// 状態を読み取れなかったリポジトリ (status が nil) は条件があれば除く。
+func (f StatusFilter) Match(status *WorkStatus) bool {
+	if f.IsZero() {
+		return true
+	}
+	if status == nil {
+		return false
+	}
+	return (f.Dirty && status.IsDirty()) || (f.Ahead && status.HasUnpushed())
+}
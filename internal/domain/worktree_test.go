@@ -0,0 +1,41 @@
+package domain_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+func TestStatusFilter_Match(t *testing.T) {
+	committed := time.Unix(1700000000, 0)
+	clean := &domain.WorkStatus{Branch: "main", Upstream: "origin/main", LastCommit: committed}
+	dirty := &domain.WorkStatus{Branch: "main", Upstream: "origin/main", Untracked: 1, LastCommit: committed}
+	ahead := &domain.WorkStatus{Branch: "main", Upstream: "origin/main", Ahead: 2, LastCommit: committed}
+	noUpstream := &domain.WorkStatus{Branch: "topic", LastCommit: committed}
+	detached := &domain.WorkStatus{LastCommit: committed}
+
+	tests := []struct {
+		name   string
+		filter domain.StatusFilter
+		status *domain.WorkStatus
+		want   bool
+	}{
+		{"条件なし", domain.StatusFilter{}, nil, true},
+		{"--dirty 変更なし", domain.StatusFilter{Dirty: true}, clean, false},
+		{"--dirty 未追跡あり", domain.StatusFilter{Dirty: true}, dirty, true},
+		{"--ahead 同期済み", domain.StatusFilter{Ahead: true}, clean, false},
+		{"--ahead 先行", domain.StatusFilter{Ahead: true}, ahead, true},
+		{"--ahead 追跡ブランチなし", domain.StatusFilter{Ahead: true}, noUpstream, true},
+		{"--ahead detached HEAD", domain.StatusFilter{Ahead: true}, detached, false},
+		{"--dirty --ahead はどちらか", domain.StatusFilter{Dirty: true, Ahead: true}, ahead, true},
+		{"状態を読み取れない", domain.StatusFilter{Dirty: true}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.status); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -8,13 +8,14 @@ import (
 	"net/url"
 	"os"
 	"os/exec"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 
 	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
-// ExitError はgh repoの終了コードを伝播するためのエラー型。
+// ExitError はghの終了コードを伝播するためのエラー型。
 type ExitError struct {
 	Code   int
 	Stderr string
@@ -38,20 +39,22 @@ func New() *Executor {
 	return &Executor{}
 }
 
-func (e *Executor) ExecRepo(profile domain.Profile, args []string) error {
-	cmd, err := buildRepoCmd(profile, args)
-	if err != nil {
-		return err
-	}
-
-	// clone + root設定時: clone先パスを追加
-	if profile.Root != "" && len(args) > 0 && args[0] == "clone" {
-		cloneDir := resolveCloneDir(profile.Root, args)
+// Exec は "gh <args...>" をプロファイルの環境で実行する。
+// args にはサブコマンドを含める (例: ["repo", "clone", "owner/repo"])。
+func (e *Executor) Exec(profile domain.Profile, args []string) error {
+	// repo clone + root設定時: clone先パスを追加 (git に渡す "--" 以降より前に置く)
+	if profile.Root != "" && len(args) > 1 && args[0] == "repo" && args[1] == "clone" {
+		cloneDir := resolveCloneDir(profile, args[1:])
 		if cloneDir != "" {
-			cmd.Args = append(cmd.Args, cloneDir)
+			args = insertBeforeDashDash(args, cloneDir)
 		}
 	}
 
+	cmd, err := buildCmd(profile, args)
+	if err != nil {
+		return err
+	}
+
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 
@@ -65,8 +68,8 @@ func (e *Executor) ExecRepo(profile domain.Profile, args []string) error {
 }
 
 // resolveCloneDir はclone先ディレクトリを決定する。
-// argsは"clone"の後の引数。"owner/repo"形式を探して{root}/owner/repoを返す。
-func resolveCloneDir(root string, args []string) string {
+// argsは"clone"の後の引数。"owner/repo"形式を探してプロファイルの layout に沿ったパスを返す。
+func resolveCloneDir(profile domain.Profile, args []string) string {
 	for _, arg := range args[1:] {
 		if arg == "--" {
 			break
@@ -79,37 +82,60 @@ func resolveCloneDir(root string, args []string) string {
 		if !strings.Contains(arg, "/") && !strings.Contains(arg, ":") {
 			continue
 		}
-		return filepath.Join(root, extractOwnerRepo(arg))
+		return profile.RepoPath(extractRepoRef(arg, profile.HostName()))
 	}
 	return ""
 }
 
-// extractOwnerRepo は引数からowner/repo部分を抽出する。
-// HTTPS URL, SSH URL, owner/repo形式に対応し、.gitサフィックスを除去する。
-func extractOwnerRepo(arg string) string {
-	// HTTPS URL: https://github.com/owner/repo.git
-	if u, err := url.Parse(arg); err == nil && u.Scheme != "" {
-		parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
-		if len(parts) >= 2 {
-			repo := strings.TrimSuffix(parts[1], ".git")
-			return parts[0] + "/" + repo
-		}
+// insertBeforeDashDash は args の "--" の直前 (無ければ末尾) に arg を挿入した引数を返す。
+func insertBeforeDashDash(args []string, arg string) []string {
+	i := slices.Index(args, "--")
+	if i < 0 {
+		i = len(args)
 	}
+	return slices.Concat(args[:i], []string{arg}, args[i:])
+}
+
+// extractRepoRef は引数から host と owner/repo を抽出する。
+// 引数にホストが含まれなければ defaultHost を使う。
+// 対応形式: owner/repo, HOST/owner/repo, https://HOST/owner/repo(.git), git@HOST:owner/repo(.git)
+func extractRepoRef(arg, defaultHost string) domain.RepoRef {
+	ref := domain.RepoRef{Host: defaultHost}
+	path := arg
 
-	// SSH URL: git@github.com:owner/repo.git
-	if strings.Contains(arg, ":") && !strings.Contains(arg, "://") {
+	switch u, err := url.Parse(arg); {
+	case err == nil && u.Scheme != "":
+		// HTTPS URL: https://github.com/owner/repo.git
+		ref.Host = u.Hostname()
+		path = strings.TrimPrefix(u.Path, "/")
+	case strings.Contains(arg, ":"):
+		// SSH URL: git@github.com:owner/repo.git
 		colonIdx := strings.Index(arg, ":")
-		path := arg[colonIdx+1:]
-		path = strings.TrimSuffix(path, ".git")
-		return path
+		host := arg[:colonIdx]
+		if at := strings.LastIndex(host, "@"); at >= 0 {
+			host = host[at+1:]
+		}
+		ref.Host = host
+		path = arg[colonIdx+1:]
+	default:
+		// HOST/owner/repo (GHE等)
+		if parts := strings.Split(arg, "/"); len(parts) == 3 {
+			ref.Host = parts[0]
+			path = parts[1] + "/" + parts[2]
+		}
 	}
 
-	// owner/repo or owner/repo.git
-	return strings.TrimSuffix(arg, ".git")
+	parts := strings.Split(strings.TrimSuffix(path, ".git"), "/")
+	ref.Owner = parts[0]
+	if len(parts) >= 2 {
+		ref.Repo = strings.TrimSuffix(parts[1], ".git")
+	}
+	return ref
 }
 
-func (e *Executor) ExecRepoCapture(profile domain.Profile, args []string) (string, error) {
-	cmd, err := buildRepoCmd(profile, args)
+// ExecCapture は "gh <args...>" を実行し、標準出力を返す。
+func (e *Executor) ExecCapture(profile domain.Profile, args []string) (string, error) {
+	cmd, err := buildCmd(profile, args)
 	if err != nil {
 		return "", err
 	}
@@ -124,18 +150,143 @@ func (e *Executor) ExecRepoCapture(profile domain.Profile, args []string) (strin
 	return string(out), nil
 }
 
-// buildRepoCmd は "gh repo ..." コマンドを構築する。
-func buildRepoCmd(profile domain.Profile, args []string) (*exec.Cmd, error) {
-	ghArgs := append([]string{"repo"}, args...)
+// GHVersion は gh --version の出力からバージョン番号を返す。
+func (e *Executor) GHVersion() (string, error) {
+	ghPath, err := exec.LookPath("gh")
+	if err != nil {
+		return "", fmt.Errorf("gh command not found: %w", err)
+	}
+	out, err := exec.Command(ghPath, "--version").Output()
+	if err != nil {
+		return "", wrapExitError(err)
+	}
+	return parseGHVersion(string(out))
+}
+
+// parseGHVersion は "gh version 2.45.0 (2024-03-04)" 形式の出力からバージョン番号を取り出す。
+func parseGHVersion(out string) (string, error) {
+	fields := strings.Fields(out)
+	if len(fields) < 3 || fields[0] != "gh" || fields[1] != "version" {
+		line, _, _ := strings.Cut(out, "\n")
+		return "", fmt.Errorf("unexpected gh --version output: %q", line)
+	}
+	return fields[2], nil
+}
+
+// buildCmd は "gh <args...>" コマンドを構築する。プロファイルの defaults の引数を補う。
+func buildCmd(profile domain.Profile, args []string) (*exec.Cmd, error) {
 	ghPath, err := exec.LookPath("gh")
 	if err != nil {
 		return nil, fmt.Errorf("gh command not found: %w", err)
 	}
-	cmd := exec.Command(ghPath, ghArgs...)
-	cmd.Env = appendEnv(os.Environ(), "GH_CONFIG_DIR", profile.GHConfigDir)
+	env, err := ghEnv(profile)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(ghPath, profile.WithDefaults(args)...)
+	cmd.Env = env
 	return cmd, nil
 }
 
+// ExecCommand は argv をプロファイルの環境で実行する。
+// gh 用の環境変数に加え、git の認証情報 (GIT_SSH_COMMAND, GIT_AUTHOR_*, GIT_COMMITTER_*) を設定する。
+func (e *Executor) ExecCommand(profile domain.Profile, argv []string) error {
+	if len(argv) == 0 {
+		return errors.New("no command specified")
+	}
+	env, err := ghEnv(profile)
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(argv[0], argv[1:]...)
+	cmd.Env = gitEnv(env, profile)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return wrapExitError(err)
+	}
+	return nil
+}
+
+// ghEnv は gh をプロファイルのアカウントで実行するための環境変数を返す。
+func ghEnv(profile domain.Profile) ([]string, error) {
+	env := profileEnv(os.Environ(), profile)
+
+	// user指定時: アクティブでないアカウントも使えるようトークンを明示する
+	if profile.User != "" {
+		ghPath, err := exec.LookPath("gh")
+		if err != nil {
+			return nil, fmt.Errorf("gh command not found: %w", err)
+		}
+		token, err := accountToken(ghPath, profile)
+		if err != nil {
+			return nil, err
+		}
+		env = appendEnv(env, tokenEnvKey(profile.HostName()), token)
+	}
+	return env, nil
+}
+
+// gitEnv は env に git の SSH 鍵とコミット作者情報を設定して返す。
+// プロファイルで未設定の項目は変更しない。
+func gitEnv(env []string, profile domain.Profile) []string {
+	if sshCmd := profile.SSHCommand(); sshCmd != "" {
+		env = appendEnv(env, "GIT_SSH_COMMAND", sshCmd)
+	}
+	if profile.GitConfigName != "" {
+		env = appendEnv(env, "GIT_AUTHOR_NAME", profile.GitConfigName)
+		env = appendEnv(env, "GIT_COMMITTER_NAME", profile.GitConfigName)
+	}
+	if profile.GitConfigEmail != "" {
+		env = appendEnv(env, "GIT_AUTHOR_EMAIL", profile.GitConfigEmail)
+		env = appendEnv(env, "GIT_COMMITTER_EMAIL", profile.GitConfigEmail)
+	}
+	return env
+}
+
+// accountToken は gh auth token でプロファイルの user のトークンを取得する。
+func accountToken(ghPath string, profile domain.Profile) (string, error) {
+	cmd := exec.Command(ghPath, "auth", "token", "--hostname", profile.HostName(), "--user", profile.User)
+	cmd.Env = profileEnv(os.Environ(), profile)
+
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get token for %s on %s: %w",
+			profile.User, profile.HostName(), wrapExitErrorWithStderr(err, stderrBuf.String()))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// tokenEnvKey は host に対して gh が参照するトークンの環境変数名を返す。
+// github.com と *.ghe.com は GH_TOKEN、GitHub Enterprise Server は GH_ENTERPRISE_TOKEN。
+func tokenEnvKey(host string) string {
+	if host == domain.DefaultHost || strings.HasSuffix(host, ".ghe.com") {
+		return "GH_TOKEN"
+	}
+	return "GH_ENTERPRISE_TOKEN"
+}
+
+// profileEnv は env にプロファイルの env と GH_CONFIG_DIR、GH_HOST を設定して返す。
+// env で GH_CONFIG_DIR や GH_HOST を指定しても、プロファイルの値が優先される。
+func profileEnv(env []string, profile domain.Profile) []string {
+	keys := make([]string, 0, len(profile.Env))
+	for key := range profile.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = appendEnv(env, key, profile.Env[key])
+	}
+	env = appendEnv(env, "GH_CONFIG_DIR", profile.GHConfigDir)
+	return appendEnv(env, "GH_HOST", profile.HostName())
+}
+
 // wrapExitError は exec.ExitError を executor.ExitError に変換する。
 func wrapExitError(err error) error {
 	var exitErr *exec.ExitError
@@ -4,52 +4,68 @@ import (
 	"errors"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
-func TestExtractOwnerRepo(t *testing.T) {
+func TestExtractRepoRef(t *testing.T) {
+	github := func(host string) domain.RepoRef {
+		return domain.RepoRef{Host: host, Owner: "sarrrrry", Repo: "init-setup"}
+	}
 	tests := []struct {
 		name string
 		arg  string
-		want string
+		want domain.RepoRef
 	}{
 		{
 			name: "owner/repo形式",
 			arg:  "sarrrrry/init-setup",
-			want: "sarrrrry/init-setup",
+			want: github("default.example.com"),
 		},
 		{
 			name: "owner/repo.git形式",
 			arg:  "sarrrrry/init-setup.git",
-			want: "sarrrrry/init-setup",
+			want: github("default.example.com"),
 		},
 		{
 			name: "HTTPS URL (.gitあり)",
 			arg:  "https://github.com/sarrrrry/init-setup.git",
-			want: "sarrrrry/init-setup",
+			want: github("github.com"),
 		},
 		{
 			name: "HTTPS URL (.gitなし)",
 			arg:  "https://github.com/sarrrrry/init-setup",
-			want: "sarrrrry/init-setup",
+			want: github("github.com"),
 		},
 		{
 			name: "SSH URL (.gitあり)",
 			arg:  "git@github.com:sarrrrry/init-setup.git",
-			want: "sarrrrry/init-setup",
+			want: github("github.com"),
 		},
 		{
 			name: "SSH URL (.gitなし)",
 			arg:  "git@github.com:sarrrrry/init-setup",
-			want: "sarrrrry/init-setup",
+			want: github("github.com"),
+		},
+		{
+			name: "HOST/owner/repo形式",
+			arg:  "ghe.example.com/sarrrrry/init-setup",
+			want: github("ghe.example.com"),
+		},
+		{
+			name: "GHE HTTPS URL",
+			arg:  "https://ghe.example.com/sarrrrry/init-setup.git",
+			want: github("ghe.example.com"),
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractOwnerRepo(tt.arg)
+			got := extractRepoRef(tt.arg, "default.example.com")
 			if got != tt.want {
-				t.Errorf("extractOwnerRepo(%q) = %q, want %q", tt.arg, got, tt.want)
+				t.Errorf("extractRepoRef(%q) = %+v, want %+v", tt.arg, got, tt.want)
 			}
 		})
 	}
@@ -191,10 +207,202 @@ func TestResolveCloneDir(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := resolveCloneDir(root, tt.args)
+			got := resolveCloneDir(domain.Profile{Root: root}, tt.args)
 			if got != tt.want {
 				t.Errorf("resolveCloneDir(%q, %v) = %q, want %q", root, tt.args, got, tt.want)
 			}
 		})
 	}
 }
+
+func TestResolveCloneDir_Layout(t *testing.T) {
+	ghq, err := domain.ParseLayout("{host}/{owner}/{repo}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	flat, err := domain.ParseLayout("{owner}-{repo}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name    string
+		profile domain.Profile
+		arg     string
+		want    string
+	}{
+		{
+			name:    "ghq形式はプロファイルのホストを補う",
+			profile: domain.Profile{Root: "/ghq", Layout: ghq},
+			arg:     "sarrrrry/init-setup",
+			want:    filepath.Join("/ghq", "github.com", "sarrrrry", "init-setup"),
+		},
+		{
+			name:    "ghq形式でGHEのホストを使う",
+			profile: domain.Profile{Root: "/ghq", Host: "ghe.example.com", Layout: ghq},
+			arg:     "sarrrrry/init-setup",
+			want:    filepath.Join("/ghq", "ghe.example.com", "sarrrrry", "init-setup"),
+		},
+		{
+			name:    "URLのホストを優先する",
+			profile: domain.Profile{Root: "/ghq", Layout: ghq},
+			arg:     "git@ghe.example.com:sarrrrry/init-setup.git",
+			want:    filepath.Join("/ghq", "ghe.example.com", "sarrrrry", "init-setup"),
+		},
+		{
+			name:    "1階層のlayout",
+			profile: domain.Profile{Root: "/repos", Layout: flat},
+			arg:     "sarrrrry/init-setup",
+			want:    filepath.Join("/repos", "sarrrrry-init-setup"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := resolveCloneDir(tt.profile, []string{"clone", tt.arg})
+			if got != tt.want {
+				t.Errorf("resolveCloneDir() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileEnv(t *testing.T) {
+	t.Run("GH_CONFIG_DIRとGH_HOSTを設定する", func(t *testing.T) {
+		profile := domain.Profile{Name: "work", GHConfigDir: "/path/work", Host: "ghe.example.com"}
+		got := profileEnv([]string{"PATH=/usr/bin", "GH_HOST=github.com"}, profile)
+
+		want := []string{"PATH=/usr/bin", "GH_HOST=ghe.example.com", "GH_CONFIG_DIR=/path/work"}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("env = %v, want %v", got, want)
+		}
+	})
+
+	t.Run("host未設定の場合はgithub.com", func(t *testing.T) {
+		profile := domain.Profile{Name: "personal", GHConfigDir: "/path/personal"}
+		got := profileEnv(nil, profile)
+
+		if !strings.Contains(strings.Join(got, "\n"), "GH_HOST=github.com") {
+			t.Errorf("env = %v, want GH_HOST=github.com", got)
+		}
+	})
+
+	t.Run("プロファイルのenvを追加する", func(t *testing.T) {
+		profile := domain.Profile{
+			Name:        "work",
+			GHConfigDir: "/path/work",
+			Env:         map[string]string{"HTTPS_PROXY": "http://proxy:8080", "GH_PAGER": "cat", "GH_CONFIG_DIR": "/ignored"},
+		}
+		got := profileEnv([]string{"GH_PAGER=less"}, profile)
+
+		want := []string{"GH_PAGER=cat", "GH_CONFIG_DIR=/path/work", "HTTPS_PROXY=http://proxy:8080", "GH_HOST=github.com"}
+		if strings.Join(got, "\n") != strings.Join(want, "\n") {
+			t.Errorf("env = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestInsertBeforeDashDash(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "-- なし", args: []string{"repo", "clone", "o/r"}, want: "repo clone o/r /root/o/r"},
+		{name: "-- の前", args: []string{"repo", "clone", "o/r", "--", "--depth", "1"}, want: "repo clone o/r /root/o/r -- --depth 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := strings.Join(insertBeforeDashDash(tt.args, "/root/o/r"), " ")
+			if got != tt.want {
+				t.Errorf("insertBeforeDashDash = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitEnv(t *testing.T) {
+	t.Run("SSH鍵とコミット作者情報を設定する", func(t *testing.T) {
+		profile := domain.Profile{
+			Name:           "work",
+			GHConfigDir:    "/path/work",
+			GitConfigName:  "Work User",
+			GitConfigEmail: "work@example.com",
+			SSHIdentity:    "/home/user/.ssh/id_work",
+		}
+		got := strings.Join(gitEnv(nil, profile), "\n")
+
+		for _, want := range []string{
+			"GIT_SSH_COMMAND=ssh -i /home/user/.ssh/id_work -o IdentitiesOnly=yes",
+			"GIT_AUTHOR_NAME=Work User",
+			"GIT_COMMITTER_NAME=Work User",
+			"GIT_AUTHOR_EMAIL=work@example.com",
+			"GIT_COMMITTER_EMAIL=work@example.com",
+		} {
+			if !strings.Contains(got, want) {
+				t.Errorf("env should contain %q, got:\n%s", want, got)
+			}
+		}
+	})
+
+	t.Run("未設定の項目は変更しない", func(t *testing.T) {
+		profile := domain.Profile{Name: "personal", GHConfigDir: "/path/personal"}
+		env := []string{"GIT_SSH_COMMAND=ssh -v"}
+		got := gitEnv(env, profile)
+
+		if len(got) != 1 || got[0] != "GIT_SSH_COMMAND=ssh -v" {
+			t.Errorf("env = %v, want unchanged", got)
+		}
+	})
+}
+
+func TestExecCommand_PropagatesExitCode(t *testing.T) {
+	profile := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+	err := New().ExecCommand(profile, []string{"sh", "-c", "exit 3"})
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) {
+		t.Fatalf("should return *ExitError, got %T", err)
+	}
+	if exitErr.Code != 3 {
+		t.Errorf("Code = %d, want 3", exitErr.Code)
+	}
+}
+
+func TestExecCommand_NoCommand(t *testing.T) {
+	profile := domain.Profile{Name: "work", GHConfigDir: "/path/work"}
+	if err := New().ExecCommand(profile, nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestTokenEnvKey(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+	}{
+		{"github.com", "GH_TOKEN"},
+		{"octo.ghe.com", "GH_TOKEN"},
+		{"ghe.example.com", "GH_ENTERPRISE_TOKEN"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			if got := tokenEnvKey(tt.host); got != tt.want {
+				t.Errorf("tokenEnvKey(%q) = %q, want %q", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGHVersion(t *testing.T) {
+	got, err := parseGHVersion("gh version 2.45.0 (2024-03-04)\nhttps://github.com/cli/cli/releases/tag/v2.45.0\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "2.45.0" {
+		t.Errorf("version = %q, want %q", got, "2.45.0")
+	}
+
+	if _, err := parseGHVersion("something else"); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
@@ -0,0 +1,119 @@
+package executor
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// Git はローカルリポジトリの git 設定を読み取る。
+type Git struct{}
+
+func NewGit() *Git {
+	return &Git{}
+}
+
+// TopLevel は dir を含むリポジトリのルートを返す。
+func (g *Git) TopLevel(dir string) (string, error) {
+	out, err := runGit(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// LocalConfig はリポジトリローカルの git config の値を返す。未設定の場合は空文字列を返す。
+func (g *Git) LocalConfig(dir, key string) (string, error) {
+	out, err := runGit(dir, "config", "--local", "--get", key)
+	if err != nil {
+		// git config --get はキーが存在しない場合に終了コード1を返す
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) && exitErr.Code == 1 {
+			return "", nil
+		}
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// SetLocalConfig はリポジトリローカルの git config に値を書き込む。
+func (g *Git) SetLocalConfig(dir, key, value string) error {
+	_, err := runGit(dir, "config", "--local", key, value)
+	return err
+}
+
+// UnsetLocalConfig はリポジトリローカルの git config から値を消す。未設定なら何もしない。
+func (g *Git) UnsetLocalConfig(dir, key string) error {
+	_, err := runGit(dir, "config", "--local", "--unset", key)
+	// git config --unset はキーが存在しない場合に終了コード5を返す
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Code == 5 {
+		return nil
+	}
+	return err
+}
+
+func runGit(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+
+	var stderrBuf bytes.Buffer
+	cmd.Stderr = &stderrBuf
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", wrapExitErrorWithStderr(err, stderrBuf.String())
+	}
+	return string(out), nil
+}
+
+// WorkStatus は作業ツリーの状態を git status、git stash list、git log から読み取る。
+func (g *Git) WorkStatus(dir string) (domain.WorkStatus, error) {
+	out, err := runGit(dir, "status", "--porcelain=v2", "--branch")
+	if err != nil {
+		return domain.WorkStatus{}, err
+	}
+	st := parseStatus(out)
+
+	if out, err := runGit(dir, "stash", "list"); err == nil {
+		st.Stashes = countLines(out)
+	}
+	// コミットのないリポジトリでは git log が失敗する
+	if out, err := runGit(dir, "log", "-1", "--format=%ct"); err == nil {
+		if sec, err := strconv.ParseInt(strings.TrimSpace(out), 10, 64); err == nil {
+			st.LastCommit = time.Unix(sec, 0)
+		}
+	}
+	return st, nil
+}
+
+// parseStatus は git status --porcelain=v2 --branch の出力を解釈する。
+func parseStatus(out string) domain.WorkStatus {
+	var st domain.WorkStatus
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "# branch.head "):
+			if head := strings.TrimPrefix(line, "# branch.head "); head != "(detached)" {
+				st.Branch = head
+			}
+		case strings.HasPrefix(line, "# branch.upstream "):
+			st.Upstream = strings.TrimPrefix(line, "# branch.upstream ")
+		case strings.HasPrefix(line, "# branch.ab "):
+			_, _ = fmt.Sscanf(strings.TrimPrefix(line, "# branch.ab "), "+%d -%d", &st.Ahead, &st.Behind)
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			st.Dirty++
+		case strings.HasPrefix(line, "? "):
+			st.Untracked++
+		}
+	}
+	return st
+}
+
+func countLines(s string) int {
+	return len(strings.FieldsFunc(s, func(r rune) bool { return r == '\n' }))
+}
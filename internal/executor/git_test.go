@@ -0,0 +1,143 @@
+package executor_test
+
+import (
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/executor"
+)
+
+func TestGit_LocalConfig(t *testing.T) {
+	dir := initRepo(t)
+	gitCmd(t, dir, "config", "--local", "user.email", "work@example.com")
+
+	g := executor.NewGit()
+
+	got, err := g.LocalConfig(dir, "user.email")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "work@example.com" {
+		t.Errorf("user.email = %q, want %q", got, "work@example.com")
+	}
+
+	got, err = g.LocalConfig(dir, "core.sshCommand")
+	if err != nil {
+		t.Fatalf("unset key should not be an error: %v", err)
+	}
+	if got != "" {
+		t.Errorf("core.sshCommand = %q, want empty", got)
+	}
+}
+
+func TestGit_SetLocalConfig(t *testing.T) {
+	dir := initRepo(t)
+	g := executor.NewGit()
+
+	if err := g.SetLocalConfig(dir, "user.email", "work@example.com"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := g.LocalConfig(dir, "user.email"); got != "work@example.com" {
+		t.Errorf("user.email = %q, want %q", got, "work@example.com")
+	}
+
+	if err := g.UnsetLocalConfig(dir, "user.email"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, _ := g.LocalConfig(dir, "user.email"); got != "" {
+		t.Errorf("user.email = %q, want empty", got)
+	}
+	// 設定されていないキーを消してもエラーにしない
+	if err := g.UnsetLocalConfig(dir, "user.email"); err != nil {
+		t.Errorf("unset of missing key should not be an error: %v", err)
+	}
+}
+
+func TestGit_TopLevel(t *testing.T) {
+	dir := initRepo(t)
+	mkDir(t, dir, "sub/dir")
+
+	got, err := executor.NewGit().TopLevel(filepath.Join(dir, "sub", "dir"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := filepath.EvalSymlinks(dir)
+	if got != want {
+		t.Errorf("TopLevel = %q, want %q", got, want)
+	}
+}
+
+func TestGit_TopLevel_NotRepository(t *testing.T) {
+	if _, err := executor.NewGit().TopLevel(t.TempDir()); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestGit_WorkStatus(t *testing.T) {
+	upstream := initRepo(t)
+	commit(t, upstream, "README.md", "v1")
+
+	dir := t.TempDir()
+	gitCmd(t, dir, "clone", "-q", upstream, ".")
+	commit(t, dir, "a.txt", "local")
+	commit(t, dir, "b.txt", "local")
+	// 変更、未追跡、stash
+	writeFile(t, dir, "stashed.txt", "x")
+	gitCmd(t, dir, "add", "stashed.txt")
+	gitCmd(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "stash", "-q")
+	writeFile(t, dir, "README.md", "changed")
+	writeFile(t, dir, "new.txt", "new")
+
+	got, err := executor.NewGit().WorkStatus(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got.Upstream != "origin/"+got.Branch || got.Branch == "" {
+		t.Errorf("Branch = %q, Upstream = %q", got.Branch, got.Upstream)
+	}
+	if got.Dirty != 1 || got.Untracked != 1 || got.Ahead != 2 || got.Behind != 0 || got.Stashes != 1 {
+		t.Errorf("WorkStatus = %+v, want 1 dirty, 1 untracked, 2 ahead, 1 stash", got)
+	}
+	if got.LastCommit.IsZero() {
+		t.Error("LastCommit should be set")
+	}
+}
+
+func TestGit_WorkStatus_NoCommits(t *testing.T) {
+	got, err := executor.NewGit().WorkStatus(initRepo(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got.LastCommit.IsZero() || got.Upstream != "" || got.HasUnpushed() {
+		t.Errorf("WorkStatus = %+v, want no commits and nothing unpushed", got)
+	}
+}
+
+// --- git helpers ---
+
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git command not found")
+	}
+	dir := t.TempDir()
+	gitCmd(t, dir, "init", "-q")
+	return dir
+}
+
+func gitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// commit は name に content を書いてコミットする。
+func commit(t *testing.T, dir, name, content string) {
+	t.Helper()
+	writeFile(t, dir, name, content)
+	gitCmd(t, dir, "add", name)
+	gitCmd(t, dir, "-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", name)
+}
@@ -0,0 +1,113 @@
+package executor
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// originURL は dir のリポジトリの remote.origin.url を git の設定ファイルから直接読む。
+// リポジトリごとに git を起動しないための簡易な実装で、include などは解釈しない。
+func originURL(dir string, bare bool) string {
+	gitDir := dir
+	if !bare {
+		gitDir = resolveGitDir(dir)
+	}
+	// worktree の gitdir には設定がなく、commondir が指す本体の設定を使う
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(data))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		gitDir = common
+	}
+	return readGitConfig(filepath.Join(gitDir, "config"), `remote "origin"`, "url")
+}
+
+// resolveGitDir は作業ツリー dir の git ディレクトリを返す。.git ファイル (gitdir: ...) ならその指す先を返す。
+func resolveGitDir(dir string) string {
+	dotGit := filepath.Join(dir, ".git")
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return dotGit
+	}
+	target := strings.TrimSpace(strings.TrimPrefix(string(data), "gitdir:"))
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(dir, target)
+	}
+	return target
+}
+
+// readGitConfig は git の設定ファイル path の [section] にある key の最初の値を返す。
+// section は `remote "origin"` の形で渡す。見つからなければ空文字列を返す。
+func readGitConfig(path, section, key string) string {
+	f, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer func() { _ = f.Close() }()
+
+	want := normalizeSection(section)
+	inSection := false
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if line[0] == '[' {
+			end := strings.Index(line, "]")
+			if end < 0 {
+				continue
+			}
+			inSection = normalizeSection(line[1:end]) == want
+			line = strings.TrimSpace(line[end+1:])
+			if line == "" {
+				continue
+			}
+		}
+		if !inSection {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if ok && strings.EqualFold(strings.TrimSpace(name), key) {
+			return configValue(value)
+		}
+	}
+	return ""
+}
+
+// normalizeSection は `remote "origin"` と旧形式の `remote.origin` を同じ表記にする。
+// セクション名は大文字小文字を区別せず、サブセクション名は区別する。
+func normalizeSection(s string) string {
+	s = strings.TrimSpace(s)
+	if name, sub, ok := strings.Cut(s, " "); ok {
+		return strings.ToLower(name) + "." + strings.Trim(strings.TrimSpace(sub), `"`)
+	}
+	if name, sub, ok := strings.Cut(s, "."); ok {
+		return strings.ToLower(name) + "." + strings.ToLower(sub)
+	}
+	return strings.ToLower(s)
+}
+
+// configValue は値の引用符を外し、引用符の外のコメントを除く。
+func configValue(raw string) string {
+	var b strings.Builder
+	quoted := false
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\' && i+1 < len(raw):
+			i++
+			b.WriteByte(raw[i])
+		case !quoted && (c == '#' || c == ';'):
+			return strings.TrimSpace(b.String())
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,52 @@
+package executor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadGitConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "remote origin",
+			content: "[core]\n\tbare = false\n[remote \"upstream\"]\n\turl = git@github.com:up/repo.git\n[remote \"origin\"]\n\turl = git@github.com:octocat/repo.git\n\tfetch = +refs/heads/*:refs/remotes/origin/*\n",
+			want:    "git@github.com:octocat/repo.git",
+		},
+		{
+			name:    "キーとセクション名の大文字小文字",
+			content: "[Remote \"origin\"]\n\tURL = https://github.com/octocat/repo\n",
+			want:    "https://github.com/octocat/repo",
+		},
+		{
+			name:    "引用符とコメント",
+			content: "[remote \"origin\"]\n\turl = \"https://github.com/octocat/repo.git\" ; comment\n",
+			want:    "https://github.com/octocat/repo.git",
+		},
+		{
+			name:    "旧形式のセクション",
+			content: "[remote.origin]\n\turl = git@github.com:octocat/repo.git\n",
+			want:    "git@github.com:octocat/repo.git",
+		},
+		{
+			name:    "サブセクション名は大文字小文字を区別する",
+			content: "[remote \"Origin\"]\n\turl = git@github.com:octocat/repo.git\n",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config")
+			if err := os.WriteFile(path, []byte(tt.content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if got := readGitConfig(path, `remote "origin"`, "url"); got != tt.want {
+				t.Errorf("readGitConfig() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
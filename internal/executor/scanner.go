@@ -1,10 +1,14 @@
 package executor
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
 type FsScanner struct{}
@@ -13,31 +17,110 @@ func NewFsScanner() *FsScanner {
 	return &FsScanner{}
 }
 
-func (s *FsScanner) ScanLocalRepos(root string) ([]string, error) {
-	owners, err := os.ReadDir(root)
+// ScanLocalRepos は root 配下の git リポジトリを opts.Depth 階層までたどり、origin とともに返す。
+// 見つけたリポジトリの中はたどらない。隠しディレクトリと opts.Exclude に一致するディレクトリは無視する。
+// root 直下のディレクトリ (owner) ごとに並行して探す。
+func (s *FsScanner) ScanLocalRepos(root string, opts domain.ScanOptions) ([]domain.RepoDir, error) {
+	entries, err := os.ReadDir(root)
 	if err != nil {
 		return nil, err
 	}
 
-	var repos []string
-	for _, owner := range owners {
-		if !owner.IsDir() || strings.HasPrefix(owner.Name(), ".") {
-			continue
+	children := subdirs(entries, "", opts)
+	found := make([][]domain.RepoDir, len(children))
+	var wg sync.WaitGroup
+	for i, rel := range children {
+		wg.Add(1)
+		go func(idx int, rel string) {
+			defer wg.Done()
+			found[idx] = walkRepos(root, rel, 1, opts)
+		}(i, rel)
+	}
+	wg.Wait()
+
+	var repos []domain.RepoDir
+	for _, r := range found {
+		repos = append(repos, r...)
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Path < repos[j].Path })
+	return repos, nil
+}
+
+// walkRepos は root からの相対パス rel (depth 階層目) 以下のリポジトリを返す。
+func walkRepos(root, rel string, depth int, opts domain.ScanOptions) []domain.RepoDir {
+	dir := filepath.Join(root, filepath.FromSlash(rel))
+	if bare, ok := gitRepo(dir); ok {
+		ref, ok := opts.Ref(rel, bare)
+		if !ok {
+			return nil
+		}
+		repo := domain.RepoDir{RepoRef: ref, Path: dir, Bare: bare}
+		if raw := originURL(dir, bare); raw != "" {
+			origin, err := domain.ParseRemote(raw)
+			if err != nil {
+				origin = domain.Remote{URL: raw}
+			}
+			repo.Origin = origin
 		}
+		return []domain.RepoDir{repo}
+	}
+	if depth >= opts.Depth {
+		return nil
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var repos []domain.RepoDir
+	for _, child := range subdirs(entries, rel, opts) {
+		repos = append(repos, walkRepos(root, child, depth+1, opts)...)
+	}
+	return repos
+}
 
-		repoEntries, err := os.ReadDir(filepath.Join(root, owner.Name()))
-		if err != nil {
+// subdirs は entries のうちたどるディレクトリの root からの相対パスを返す。
+func subdirs(entries []os.DirEntry, rel string, opts domain.ScanOptions) []string {
+	var dirs []string
+	for _, e := range entries {
+		if !e.IsDir() || strings.HasPrefix(e.Name(), ".") {
 			continue
 		}
+		child := e.Name()
+		if rel != "" {
+			child = rel + "/" + e.Name()
+		}
+		if opts.Excluded(child) {
+			continue
+		}
+		dirs = append(dirs, child)
+	}
+	return dirs
+}
 
-		for _, repo := range repoEntries {
-			if !repo.IsDir() || strings.HasPrefix(repo.Name(), ".") {
-				continue
-			}
-			repos = append(repos, owner.Name()+"/"+repo.Name())
+// gitRepo は dir が git リポジトリかと、bare リポジトリかを返す。
+// .git ディレクトリ、worktree や submodule の .git ファイル (gitdir: ...)、bare リポジトリを認識する。
+func gitRepo(dir string) (bare, ok bool) {
+	dotGit := filepath.Join(dir, ".git")
+	if info, err := os.Stat(dotGit); err == nil {
+		if info.IsDir() {
+			return false, true
 		}
+		data, err := os.ReadFile(dotGit)
+		return false, err == nil && bytes.HasPrefix(data, []byte("gitdir:"))
 	}
+	if isFile(filepath.Join(dir, "HEAD")) && isDir(filepath.Join(dir, "objects")) && isDir(filepath.Join(dir, "refs")) {
+		return true, true
+	}
+	return false, false
+}
 
-	sort.Strings(repos)
-	return repos, nil
+func isFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
 }
@@ -1,82 +1,154 @@
 package executor_test
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"testing"
 
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
 	"github.com/sarrrrry/gh-mrepo/internal/executor"
 )
 
 func TestScanLocalRepos_Normal(t *testing.T) {
 	root := t.TempDir()
-	mkDir(t, root, "octocat/project-a")
-	mkDir(t, root, "octocat/project-b")
-	mkDir(t, root, "org/repo-x")
+	mkRepo(t, root, "octocat/project-a")
+	mkRepo(t, root, "octocat/project-b")
+	mkRepo(t, root, "org/repo-x")
 
-	scanner := executor.NewFsScanner()
-	repos, err := scanner.ScanLocalRepos(root)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
+	repos := scan(t, root, domain.Profile{})
 
 	want := []string{"octocat/project-a", "octocat/project-b", "org/repo-x"}
-	if !equal(repos, want) {
-		t.Errorf("repos = %v, want %v", repos, want)
+	if got := fullNames(repos); !equal(got, want) {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+	if want := filepath.Join(root, "octocat", "project-a"); repos[0].Path != want {
+		t.Errorf("Path = %q, want %q", repos[0].Path, want)
 	}
 }
 
-func TestScanLocalRepos_SkipsDotDirs(t *testing.T) {
+func TestScanLocalRepos_SkipsNonRepos(t *testing.T) {
 	root := t.TempDir()
-	mkDir(t, root, ".hidden/repo")
-	mkDir(t, root, "owner/.git")
-	mkDir(t, root, "owner/visible-repo")
+	mkRepo(t, root, "owner/repo")
+	mkDir(t, root, "owner/not-a-repo")
+	mkDir(t, root, ".hidden/repo/.git")
+	// ownerレベルとrepoレベルのファイル
+	writeFile(t, root, "README.md", "hi")
+	writeFile(t, root, "owner/notes.txt", "hi")
+	// gitdir: で始まらない .git ファイル
+	writeFile(t, root, "owner/broken/.git", "not a gitfile")
 
-	scanner := executor.NewFsScanner()
-	repos, err := scanner.ScanLocalRepos(root)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	repos := scan(t, root, domain.Profile{})
+
+	want := []string{"owner/repo"}
+	if got := fullNames(repos); !equal(got, want) {
+		t.Errorf("repos = %v, want %v", got, want)
 	}
+}
+
+func TestScanLocalRepos_GitfileAndBare(t *testing.T) {
+	root := t.TempDir()
+	// worktree や submodule の .git ファイル
+	writeFile(t, root, "owner/worktree/.git", "gitdir: /elsewhere/.git/worktrees/worktree\n")
+	// bare リポジトリ
+	writeFile(t, root, "owner/mirror.git/HEAD", "ref: refs/heads/main\n")
+	mkDir(t, root, "owner/mirror.git/objects")
+	mkDir(t, root, "owner/mirror.git/refs")
 
-	want := []string{"owner/visible-repo"}
-	if !equal(repos, want) {
-		t.Errorf("repos = %v, want %v", repos, want)
+	repos := scan(t, root, domain.Profile{})
+
+	want := []string{"owner/mirror", "owner/worktree"}
+	if got := fullNames(repos); !equal(got, want) {
+		t.Fatalf("repos = %v, want %v", got, want)
+	}
+	if !repos[0].Bare || repos[1].Bare {
+		t.Errorf("Bare = %v, %v, want true, false", repos[0].Bare, repos[1].Bare)
 	}
 }
 
-func TestScanLocalRepos_SkipsFiles(t *testing.T) {
+func TestScanLocalRepos_Origin(t *testing.T) {
 	root := t.TempDir()
-	mkDir(t, root, "owner/repo")
-	// ownerレベルにファイルを作成
-	if err := os.WriteFile(filepath.Join(root, "README.md"), []byte("hi"), 0o644); err != nil {
-		t.Fatal(err)
+	origin := "[remote \"origin\"]\n\turl = %s\n"
+	// 通常のリポジトリ (名前の変更後)
+	writeFile(t, root, "octocat/old-name/.git/config", fmt.Sprintf(origin, "git@github.com:octocat/new-name.git"))
+	// bare リポジトリ
+	writeFile(t, root, "octocat/mirror.git/HEAD", "ref: refs/heads/main\n")
+	writeFile(t, root, "octocat/mirror.git/config", fmt.Sprintf(origin, "https://github.com/octocat/mirror.git"))
+	mkDir(t, root, "octocat/mirror.git/objects")
+	mkDir(t, root, "octocat/mirror.git/refs")
+	// worktree は commondir が指す本体の設定を読む
+	writeFile(t, root, "octocat/old-name/.git/worktrees/wt/commondir", "../..\n")
+	writeFile(t, root, "octocat/wt/.git", "gitdir: ../old-name/.git/worktrees/wt\n")
+	// origin なし
+	mkRepo(t, root, "octocat/local-only")
+
+	repos := scan(t, root, domain.Profile{})
+
+	want := map[string]string{
+		"octocat/local-only": "",
+		"octocat/mirror":     "octocat/mirror",
+		"octocat/old-name":   "octocat/new-name",
+		"octocat/wt":         "octocat/new-name",
 	}
-	// repoレベルにファイルを作成
-	if err := os.WriteFile(filepath.Join(root, "owner", "notes.txt"), []byte("hi"), 0o644); err != nil {
-		t.Fatal(err)
+	if len(repos) != len(want) {
+		t.Fatalf("repos = %v, want %d repos", fullNames(repos), len(want))
+	}
+	for _, r := range repos {
+		got := ""
+		if r.Origin.Owner != "" {
+			got = r.Origin.FullName()
+		}
+		if got != want[r.FullName()] {
+			t.Errorf("%s: origin = %q, want %q", r.FullName(), got, want[r.FullName()])
+		}
 	}
+}
 
-	scanner := executor.NewFsScanner()
-	repos, err := scanner.ScanLocalRepos(root)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestScanLocalRepos_Depth(t *testing.T) {
+	root := t.TempDir()
+	mkRepo(t, root, "owner/repo")
+	// リポジトリの中はたどらない
+	mkRepo(t, root, "owner/repo/vendor/lib")
+	mkRepo(t, root, "group/sub/deep")
+
+	tests := []struct {
+		name  string
+		depth int
+		want  []string
+	}{
+		{"layoutの階層まで", 0, []string{"owner/repo"}},
+		{"深い階層まで", 3, []string{"sub/deep", "owner/repo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos := scan(t, root, domain.Profile{ScanDepth: tt.depth})
+			if got := fullNames(repos); !equal(got, tt.want) {
+				t.Errorf("repos = %v, want %v", got, tt.want)
+			}
+		})
 	}
+}
 
-	want := []string{"owner/repo"}
-	if !equal(repos, want) {
-		t.Errorf("repos = %v, want %v", repos, want)
+func TestScanLocalRepos_Exclude(t *testing.T) {
+	root := t.TempDir()
+	mkRepo(t, root, "archive/old")
+	mkRepo(t, root, "owner/keep")
+	mkRepo(t, root, "owner/tmp-scratch")
+	mkRepo(t, root, "other/tmp-keep")
+
+	repos := scan(t, root, domain.Profile{Exclude: []string{"archive", "owner/tmp-*"}})
+
+	want := []string{"other/tmp-keep", "owner/keep"}
+	if got := fullNames(repos); !equal(got, want) {
+		t.Errorf("repos = %v, want %v", got, want)
 	}
 }
 
 func TestScanLocalRepos_EmptyRoot(t *testing.T) {
 	root := t.TempDir()
 
-	scanner := executor.NewFsScanner()
-	repos, err := scanner.ScanLocalRepos(root)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
+	repos := scan(t, root, domain.Profile{})
 
 	if len(repos) != 0 {
 		t.Errorf("repos = %v, want empty", repos)
@@ -85,7 +157,7 @@ func TestScanLocalRepos_EmptyRoot(t *testing.T) {
 
 func TestScanLocalRepos_NonexistentRoot(t *testing.T) {
 	scanner := executor.NewFsScanner()
-	_, err := scanner.ScanLocalRepos("/nonexistent/path/that/does/not/exist")
+	_, err := scanner.ScanLocalRepos("/nonexistent/path/that/does/not/exist", domain.Profile{}.ScanOptions())
 	if err == nil {
 		t.Fatal("expected error for nonexistent root, got nil")
 	}
@@ -93,29 +165,85 @@ func TestScanLocalRepos_NonexistentRoot(t *testing.T) {
 
 func TestScanLocalRepos_SortedOutput(t *testing.T) {
 	root := t.TempDir()
-	mkDir(t, root, "zeta/repo")
-	mkDir(t, root, "alpha/repo")
-	mkDir(t, root, "middle/aaa")
-	mkDir(t, root, "middle/zzz")
+	mkRepo(t, root, "zeta/repo")
+	mkRepo(t, root, "alpha/repo")
+	mkRepo(t, root, "middle/aaa")
+	mkRepo(t, root, "middle/zzz")
 
-	scanner := executor.NewFsScanner()
-	repos, err := scanner.ScanLocalRepos(root)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
+	repos := scan(t, root, domain.Profile{})
 
-	if !sort.StringsAreSorted(repos) {
+	if !sort.StringsAreSorted(fullNames(repos)) {
 		t.Errorf("repos not sorted: %v", repos)
 	}
 
 	want := []string{"alpha/repo", "middle/aaa", "middle/zzz", "zeta/repo"}
-	if !equal(repos, want) {
-		t.Errorf("repos = %v, want %v", repos, want)
+	if got := fullNames(repos); !equal(got, want) {
+		t.Errorf("repos = %v, want %v", got, want)
+	}
+}
+
+func TestScanLocalRepos_Layout(t *testing.T) {
+	tests := []struct {
+		name   string
+		layout string
+		repos  []string
+		want   []domain.RepoRef
+	}{
+		{
+			name:   "ghq形式",
+			layout: "{host}/{owner}/{repo}",
+			repos:  []string{"github.com/octocat/dotfiles", "ghe.example.com/corp/api", "github.com/.cache/x"},
+			want: []domain.RepoRef{
+				{Host: "ghe.example.com", Owner: "corp", Repo: "api"},
+				{Host: "github.com", Owner: "octocat", Repo: "dotfiles"},
+			},
+		},
+		{
+			name:   "1階層の形式",
+			layout: "{owner}-{repo}",
+			repos:  []string{"octocat-dotfiles", "org-my-repo", "noseparator"},
+			want: []domain.RepoRef{
+				{Owner: "octocat", Repo: "dotfiles"},
+				{Owner: "org", Repo: "my-repo"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for _, r := range tt.repos {
+				mkRepo(t, root, r)
+			}
+			layout, err := domain.ParseLayout(tt.layout)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			repos := scan(t, root, domain.Profile{Layout: layout})
+			if len(repos) != len(tt.want) {
+				t.Fatalf("repos = %+v, want %+v", repos, tt.want)
+			}
+			for i := range tt.want {
+				if repos[i].RepoRef != tt.want[i] {
+					t.Errorf("repos[%d] = %+v, want %+v", i, repos[i].RepoRef, tt.want[i])
+				}
+			}
+		})
 	}
 }
 
 // --- helpers ---
 
+func scan(t *testing.T, root string, p domain.Profile) []domain.RepoDir {
+	t.Helper()
+	repos, err := executor.NewFsScanner().ScanLocalRepos(root, p.ScanOptions())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return repos
+}
+
 func mkDir(t *testing.T, base string, rel string) {
 	t.Helper()
 	if err := os.MkdirAll(filepath.Join(base, rel), 0o755); err != nil {
@@ -123,6 +251,28 @@ func mkDir(t *testing.T, base string, rel string) {
 	}
 }
 
+// mkRepo は .git ディレクトリを持つリポジトリを作る。
+func mkRepo(t *testing.T, base string, rel string) {
+	t.Helper()
+	mkDir(t, base, filepath.Join(rel, ".git"))
+}
+
+func writeFile(t *testing.T, base, rel, content string) {
+	t.Helper()
+	mkDir(t, base, filepath.Dir(rel))
+	if err := os.WriteFile(filepath.Join(base, rel), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func fullNames(repos []domain.RepoDir) []string {
+	names := make([]string, len(repos))
+	for i, r := range repos {
+		names[i] = r.FullName()
+	}
+	return names
+}
+
 func equal(a, b []string) bool {
 	if len(a) != len(b) {
 		return false
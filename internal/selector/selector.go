@@ -1,13 +1,18 @@
 package selector
 
 import (
+	"slices"
+
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
-var activeLabel = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("✓ active")
+var (
+	activeLabel = lipgloss.NewStyle().Foreground(lipgloss.Color("2")).Render("✓ active")
+	tagStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("6"))
+)
 
 type Selector struct{}
 
@@ -26,16 +31,34 @@ func (s *Selector) SelectForSwitch(profiles []domain.Profile, activeIdx int) (do
 }
 
 func (s *Selector) selectWithOptions(profiles []domain.Profile, title string, activeIdx int) (domain.Profile, error) {
-	options := make([]huh.Option[int], len(profiles))
-	for i, p := range profiles {
-		label := p.Name
-		if p.Root != "" {
-			label += " (" + p.Root + ")"
+	label := func(i int) string {
+		label := profiles[i].Name
+		if profiles[i].Root != "" {
+			label += " (" + profiles[i].Root + ")"
 		}
 		if i == activeIdx {
 			label += " " + activeLabel
 		}
-		options[i] = huh.NewOption(label, i)
+		return label
+	}
+
+	var options []huh.Option[int]
+	if len(domain.Tags(profiles)) == 0 {
+		for i := range profiles {
+			options = append(options, huh.NewOption(label(i), i))
+		}
+	} else {
+		// タグごとにまとめて並べる。タグ名を入力すればそのグループに絞り込める
+		for _, g := range domain.GroupByTag(profiles) {
+			for _, p := range g.Profiles {
+				i := slices.IndexFunc(profiles, func(q domain.Profile) bool { return q.Name == p.Name })
+				prefix := ""
+				if g.Tag != "" {
+					prefix = tagStyle.Render("#"+g.Tag) + " "
+				}
+				options = append(options, huh.NewOption(prefix+label(i), i))
+			}
+		}
 	}
 
 	var selected int
@@ -0,0 +1,65 @@
+package selector
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
+)
+
+// InitWizard は提案されたプロファイルから作成するものを選ばせ、
+// 名前・root・git の identity・SSH 鍵を編集させた結果を返す。
+// labels[i] は suggestions[i] の元になったアカウントの表示名。
+func (s *Selector) InitWizard(suggestions []domain.Profile, labels []string) ([]domain.Profile, error) {
+	options := make([]huh.Option[int], len(suggestions))
+	for i := range suggestions {
+		options[i] = huh.NewOption(labels[i], i).Selected(true)
+	}
+
+	var chosen []int
+	err := huh.NewMultiSelect[int]().
+		Title("Create profiles for these gh accounts").
+		Options(options...).
+		Value(&chosen).
+		Validate(func(v []int) error {
+			if len(v) == 0 {
+				return errors.New("select at least one account")
+			}
+			return nil
+		}).
+		Run()
+	if err != nil {
+		return nil, err
+	}
+
+	profiles := make([]domain.Profile, len(chosen))
+	groups := make([]*huh.Group, len(chosen))
+	for i, idx := range chosen {
+		profiles[i] = suggestions[idx]
+		p := &profiles[i]
+		groups[i] = huh.NewGroup(
+			huh.NewNote().Title(labels[idx]),
+			huh.NewInput().Title("Profile name").Value(&p.Name).Validate(func(name string) error {
+				if name == "" {
+					return domain.ErrEmptyName
+				}
+				for j := range profiles {
+					if j != i && profiles[j].Name == name {
+						return fmt.Errorf("profile %q is already used", name)
+					}
+				}
+				return nil
+			}),
+			huh.NewInput().Title("Root directory").Description("where repositories are cloned").Value(&p.Root),
+			huh.NewInput().Title("git user.name").Value(&p.GitConfigName),
+			huh.NewInput().Title("git user.email").Value(&p.GitConfigEmail),
+			huh.NewInput().Title("SSH key").Description("leave empty to use HTTPS").Value(&p.SSHIdentity),
+		)
+	}
+	if err := huh.NewForm(groups...).Run(); err != nil {
+		return nil, err
+	}
+	return profiles, nil
+}
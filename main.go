@@ -9,7 +9,9 @@ import (
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/sarrrrry/gh-mrepo/internal/app"
 	"github.com/sarrrrry/gh-mrepo/internal/config"
@@ -30,8 +32,8 @@ func exitOnErr(err error) {
 		var checker authChecker
 		if errors.As(profileErr.Err, &checker) && checker.IsAuthError() {
 			fmt.Fprintf(os.Stderr,
-				"\nhint: authentication failed for profile %q. Run the following command to re-authenticate:\n  GH_CONFIG_DIR=%s gh auth login\n",
-				profileErr.Profile.Name, profileErr.Profile.GHConfigDir)
+				"\nhint: authentication failed for profile %q. Run the following command to re-authenticate:\n  %s\n",
+				profileErr.Profile.Name, profileErr.Profile.AuthLoginCommand())
 		}
 	}
 
@@ -48,27 +50,106 @@ func exitOnErr(err error) {
 }
 
 func main() {
-	user, args := extractUserFlag(os.Args[1:])
-	if user == "" {
-		user = os.Getenv("GH_MREPO_PROFILE")
-	}
+	flagConfig, args := extractConfigFlag(os.Args[1:])
+	flagUser, args := extractUserFlag(args)
+	args, lenient := extractLenientFlag(args)
 
 	home, err := os.UserHomeDir()
 	exitOnErr(err)
-	configPath := filepath.Join(home, ".config", "gh-mrepo", "config.toml")
+	configPath, err := config.ResolvePath(flagConfig, os.Getenv, home)
+	exitOnErr(err)
 
 	if len(args) > 0 && args[0] == "init" {
-		exitOnErr(config.NewInitializer().Init(configPath))
+		exitOnErr(runInit(configPath, home, slices.Contains(args[1:], "--non-interactive")))
 		fmt.Printf("config.toml created: %s\n", configPath)
 		return
 	}
 
+	if len(args) > 0 && args[0] == "migrate" {
+		exitOnErr(runMigrate(configPath, home, args[1:]))
+		return
+	}
+
+	if len(args) > 0 && args[0] == "config" {
+		editor := config.NewEditor(configPath)
+		editor.SetLenient(lenient)
+		exitOnErr(runConfig(editor, args[1:]))
+		return
+	}
+
+	wd, _ := os.Getwd()
+	// マーカーの読み込みエラーはプロファイルを選ぶコマンドでだけ報告する
+	marker, markerErr := config.FindMarker(wd)
+	query := app.Query{Flag: flagUser, Env: os.Getenv("GH_MREPO_PROFILE"), Dir: wd, Marker: marker, MarkerErr: markerErr}
+
+	// gh status は "gh mrepo -- status" で実行する
+	if len(args) > 0 && (args[0] == "status" || args[0] == "which") {
+		reporter := app.NewStatusReporter(newLoader(configPath, lenient), config.NewHostResolver(),
+			executor.NewGit(), ghActiveUser{})
+		st, err := reporter.Status(query)
+		exitOnErr(err)
+		if extractJSONFlag(args[1:]) {
+			exitOnErr(writeJSON(st))
+			return
+		}
+		app.FormatStatus(st, os.Stdout)
+		return
+	}
+
+	if len(args) > 0 && args[0] == "doctor" {
+		e := executor.New()
+		doctor := app.NewDoctor(newLoader(configPath, lenient), config.NewHostResolver(), e, e)
+		report, err := doctor.Diagnose()
+		exitOnErr(err)
+		if extractJSONFlag(args[1:]) {
+			exitOnErr(writeJSON(report))
+		} else {
+			app.FormatDoctorReport(report, os.Stdout)
+		}
+		if report.Failed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 && args[0] == "audit" {
+		git := executor.NewGit()
+		auditor := app.NewAuditor(newLoader(configPath, lenient), executor.NewFsScanner(), git, git,
+			config.NewHostResolver())
+		report, err := auditor.Audit(slices.Contains(args[1:], "--fix"))
+		exitOnErr(err)
+		if extractJSONFlag(args[1:]) {
+			exitOnErr(writeJSON(report))
+		} else {
+			app.FormatAuditReport(report, os.Stdout)
+		}
+		if report.Failed() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(args) > 0 && args[0] == "exec" {
+		argv := args[1:]
+		if len(argv) > 0 && argv[0] == "--" {
+			argv = argv[1:]
+		}
+		runner := app.NewCommandRunner(newLoader(configPath, lenient), selector.New(), executor.New())
+		runner.SetLogOutput(os.Stderr)
+		exitOnErr(runner.Run(query, argv))
+		return
+	}
+
 	if len(args) > 0 && args[0] == "lls" {
-		allFlag, jsonFlag := extractLlsFlags(args[1:])
-		loader := config.NewLoader(configPath)
+		filter, llsArgs := extractTagFilter(args[1:])
+		allFlag, jsonFlag := extractLlsFlags(llsArgs)
+		status := extractStatusFilter(llsArgs)
+		exitOnErr(requireAllForTags(filter, allFlag))
+		loader := newLoader(configPath, lenient)
 		resolver := config.NewHostResolver()
 		scanner := executor.NewFsScanner()
 		localLister := app.NewLocalLister(loader, resolver, scanner)
+		localLister.SetInspector(executor.NewGit())
 
 		profiles, err := loader.Load()
 		exitOnErr(err)
@@ -77,94 +158,341 @@ func main() {
 		if allFlag {
 			selected = profiles
 		} else {
-			sel := selector.New()
-			p, err := sel.Select(profiles)
+			choice, err := app.ChooseProfile(profiles, query, selector.New())
 			exitOnErr(err)
-			selected = []domain.Profile{p}
+			app.ReportChoice(os.Stderr, choice)
+			selected = []domain.Profile{choice.Profile}
 		}
 
 		if jsonFlag {
-			repos := localLister.CollectLocalRepos(selected)
-			enc := json.NewEncoder(os.Stdout)
-			enc.SetIndent("", "  ")
-			exitOnErr(enc.Encode(repos))
+			repos, err := localLister.CollectLocalRepos(selected, filter, status)
+			exitOnErr(err)
+			exitOnErr(writeJSON(repos))
 			return
 		}
 
 		var buf bytes.Buffer
 		if allFlag {
-			exitOnErr(localLister.ListLocal(&buf))
+			exitOnErr(localLister.ListLocal(filter, status, &buf))
 		} else {
-			exitOnErr(localLister.ListLocalProfile(selected[0], &buf))
+			exitOnErr(localLister.ListLocalProfile(selected[0], status, &buf))
 		}
 		exitOnErr(viewInPager(buf.Bytes()))
 		return
 	}
 
 	if len(args) > 0 && args[0] == "ls" {
-		lsArgs, allFlag := extractAllFlag(args[1:])
+		filter, lsArgs := extractTagFilter(args[1:])
+		lsArgs, allFlag := extractAllFlag(lsArgs)
+		exitOnErr(requireAllForTags(filter, allFlag))
 		if allFlag {
-			loader := config.NewLoader(configPath)
+			loader := newLoader(configPath, lenient)
 			e := executor.New()
 			resolver := config.NewHostResolver()
 			lister := app.NewLister(loader, e, resolver)
 			var buf bytes.Buffer
-			exitOnErr(lister.List(lsArgs, &buf))
+			exitOnErr(lister.List(lsArgs, filter, &buf))
 			exitOnErr(viewInPager(buf.Bytes()))
 			return
 		}
-		args = append([]string{"list"}, lsArgs...)
+		args = append([]string{"repo", "list"}, lsArgs...)
 	}
 
 	if len(args) > 0 && args[0] == "switch" {
-		loader := config.NewLoader(configPath)
+		loader := newLoader(configPath, lenient)
 		profiles, err := loader.Load()
 		exitOnErr(err)
-		wd, _ := os.Getwd()
-		p, err := domain.FindByDirectory(profiles, wd)
-		if err != nil {
-			activeUser := resolveActiveUser()
-			activeIdx := -1
-			for i, prof := range profiles {
-				u, e := config.ResolveGitHubUser(prof.GHConfigDir)
-				if e == nil && u == activeUser {
-					activeIdx = i
-					break
-				}
-			}
-			sel := selector.New()
-			p, err = sel.SelectForSwitch(profiles, activeIdx)
-			exitOnErr(err)
-		}
-		username, err := config.ResolveGitHubUser(p.GHConfigDir)
+		choice, err := app.ChooseProfile(profiles, query, switchSelector{sel: selector.New()})
 		exitOnErr(err)
-		cmd := exec.Command("gh", "auth", "switch", "--user", username)
+		app.ReportChoice(os.Stderr, choice)
+		p := choice.Profile
+		username, err := config.ResolveGitHubUser(p.GHConfigDir, p.HostName(), p.User)
+		exitOnErr(err)
+		cmd := exec.Command("gh", "auth", "switch", "--hostname", p.HostName(), "--user", username)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		exitOnErr(cmd.Run())
 
-		if p.GitConfigName != "" {
-			_ = exec.Command("git", "config", "--local", "user.name", p.GitConfigName).Run()
-		}
-		if p.GitConfigEmail != "" {
-			_ = exec.Command("git", "config", "--local", "user.email", p.GitConfigEmail).Run()
-		}
-
-		if p.SSHIdentity != "" {
-			sshCmd := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes", p.SSHIdentity)
-			_ = exec.Command("git", "config", "--local", "core.sshCommand", sshCmd).Run()
-		} else {
-			_ = exec.Command("git", "config", "--local", "--unset", "core.sshCommand").Run()
+		// カレントディレクトリが git リポジトリでなければ何もしない
+		git := executor.NewGit()
+		if _, err := git.TopLevel("."); err != nil {
+			return
 		}
+		exitOnErr(app.ApplyGitSettings(git, ".", p))
 		return
 	}
 
-	loader := config.NewLoader(configPath)
+	loader := newLoader(configPath, lenient)
 	sel := selector.New()
 	exec := executor.New()
 
 	a := app.New(loader, sel, exec)
-	exitOnErr(a.Run(user, args))
+	a.SetLogOutput(os.Stderr)
+	exitOnErr(a.Run(query, args))
+}
+
+// runInit は設定ファイルを作成する。対話モードでは既存の gh アカウントから
+// プロファイルを提案し、ウィザードで編集させる。アカウントが見つからない場合や
+// 端末でない場合はテンプレートを書き出す。
+func runInit(configPath, home string, nonInteractive bool) error {
+	initializer := config.NewInitializer()
+	if nonInteractive || !isTerminal(os.Stdin) {
+		return initializer.Init(configPath)
+	}
+	if err := initializer.Check(configPath); err != nil {
+		return err
+	}
+
+	accounts := config.DiscoverAccounts(home, os.Getenv("GH_CONFIG_DIR"))
+	if len(accounts) == 0 {
+		fmt.Fprintln(os.Stderr, "no logged-in gh accounts found; writing a template instead")
+		return initializer.Init(configPath)
+	}
+	labels := make([]string, len(accounts))
+	for i, a := range accounts {
+		labels[i] = a.String()
+	}
+	profiles, err := selector.New().InitWizard(config.SuggestProfiles(accounts, home), labels)
+	if err != nil {
+		return err
+	}
+	return initializer.Write(configPath, config.RenderConfig(profiles))
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// runMigrate は共有の gh 設定ディレクトリをアカウントごとに分割する。
+// --dry-run では書き込む予定のファイルを内容付きで表示するだけにする。
+func runMigrate(configPath, home string, args []string) error {
+	from, dryRun := extractMigrateFlags(args)
+	if from == "" {
+		from = os.Getenv("GH_CONFIG_DIR")
+	}
+	if from == "" {
+		from = filepath.Join(home, ".config", "gh")
+	}
+
+	m, err := config.PlanMigration(from, home, configPath)
+	if err != nil {
+		return err
+	}
+	if dryRun {
+		m.Preview(os.Stdout)
+		return nil
+	}
+	if err := m.Apply(); err != nil {
+		return err
+	}
+	for _, a := range m.Accounts {
+		action := "added"
+		if a.Existing {
+			action = "updated"
+		}
+		fmt.Printf("%s@%s -> %s (profile %q %s)\n", a.User, a.Host, a.Dir, a.Profile, action)
+	}
+	fmt.Printf("%s was left unchanged. Run `gh mrepo doctor` to check the new profiles.\n", m.Source)
+	return nil
+}
+
+// extractMigrateFlags は migrate の引数から --from <dir> と --dry-run を取り出す。
+func extractMigrateFlags(args []string) (from string, dryRun bool) {
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "--dry-run", "-n":
+			dryRun = true
+		case "--from":
+			if i+1 < len(args) {
+				from = args[i+1]
+				i++
+			}
+		}
+	}
+	return
+}
+
+const configUsage = `usage: gh mrepo config <command>
+
+  list                                   list profiles and the file defining each
+  get <profile> [<key>]                  print a value (or every key) as written
+  set <profile> <key> <value>            set a key
+  unset <profile> <key>                  remove a key
+  add <profile> [--file <path>] <key>=<value>...
+                                         add a profile (to the main file by default)
+  remove <profile>                       remove a profile
+  edit [<profile>]                       open the file defining the profile in $EDITOR
+  show [<profile>] [--json]              print resolved profiles with the source of each value
+  schema                                 print the JSON Schema for config.toml`
+
+// runConfig は config サブコマンドを実行する。
+func runConfig(editor *config.Editor, args []string) error {
+	if len(args) == 0 {
+		return errors.New(configUsage)
+	}
+	cmd, args := args[0], args[1:]
+	switch {
+	case cmd == "list" || cmd == "ls":
+		sources, err := editor.List()
+		if err != nil {
+			return err
+		}
+		if extractJSONFlag(args) {
+			return writeJSON(sources)
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, src := range sources {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\n", src.Name, src.File)
+		}
+		return tw.Flush()
+	case cmd == "get" && len(args) == 1:
+		values, err := editor.Values(args[0])
+		if err != nil {
+			return err
+		}
+		for _, kv := range values {
+			fmt.Printf("%s = %s\n", kv.Key, kv.Value)
+		}
+		return nil
+	case cmd == "get" && len(args) == 2:
+		v, err := editor.Get(args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	case cmd == "set" && len(args) == 3:
+		return editor.Set(args[0], args[1], args[2])
+	case cmd == "unset" && len(args) == 2:
+		return editor.Unset(args[0], args[1])
+	case cmd == "add" && len(args) >= 1:
+		file, values, err := parseAddArgs(args[1:])
+		if err != nil {
+			return err
+		}
+		return editor.Add(args[0], file, values)
+	case (cmd == "remove" || cmd == "rm") && len(args) == 1:
+		return editor.Remove(args[0])
+	case cmd == "edit" && len(args) <= 1:
+		var name string
+		if len(args) == 1 {
+			name = args[0]
+		}
+		changed, err := editor.Edit(name, openInEditor)
+		if err == nil && !changed {
+			fmt.Fprintln(os.Stderr, "no changes")
+		}
+		return err
+	case cmd == "show":
+		return runConfigShow(editor, args)
+	case cmd == "schema" && len(args) == 0:
+		schema, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Printf("%s\n", schema)
+		return err
+	}
+	return errors.New(configUsage)
+}
+
+// runConfigShow は解決済みのプロファイルを TOML (--json なら JSON) で表示する。
+func runConfigShow(editor *config.Editor, args []string) error {
+	jsonFlag := extractJSONFlag(args)
+	var name string
+	for _, a := range args {
+		if a == "--json" || a == "-j" {
+			continue
+		}
+		if name != "" {
+			return errors.New(configUsage)
+		}
+		name = a
+	}
+
+	profiles, err := editor.Resolve()
+	if err != nil {
+		return err
+	}
+	if name != "" {
+		i := slices.IndexFunc(profiles, func(p config.ResolvedProfile) bool { return p.Name == name })
+		if i < 0 {
+			return fmt.Errorf("profile %q not found", name)
+		}
+		profiles = profiles[i : i+1]
+	}
+
+	if jsonFlag {
+		return writeJSON(profiles)
+	}
+	home, _ := os.UserHomeDir()
+	_, err = os.Stdout.Write(config.RenderResolved(profiles, home))
+	return err
+}
+
+// parseAddArgs は config add の引数から --file と key=value の組を取り出す。
+func parseAddArgs(args []string) (string, []config.KeyValue, error) {
+	var file string
+	var values []config.KeyValue
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--file" {
+			if i+1 >= len(args) {
+				return "", nil, errors.New("--file requires a path")
+			}
+			file = args[i+1]
+			i++
+			continue
+		}
+		key, value, ok := strings.Cut(args[i], "=")
+		if !ok || key == "" {
+			return "", nil, fmt.Errorf("invalid argument %q: expected <key>=<value>", args[i])
+		}
+		values = append(values, config.KeyValue{Key: key, Value: value})
+	}
+	return file, values, nil
+}
+
+// openInEditor は $VISUAL / $EDITOR (未設定なら vi) で path を開く。
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+// switchSelector は現在アクティブなアカウントを強調して表示するセレクタ。
+type switchSelector struct {
+	sel *selector.Selector
+}
+
+func (s switchSelector) Select(profiles []domain.Profile) (domain.Profile, error) {
+	return s.sel.SelectForSwitch(profiles, activeProfileIndex(profiles))
+}
+
+// activeProfileIndex は gh のアクティブアカウントと一致するプロファイルのインデックスを返す。
+// 一致しない場合は -1 を返す。
+func activeProfileIndex(profiles []domain.Profile) int {
+	activeUsers := make(map[string]string)
+	for i, prof := range profiles {
+		host := prof.HostName()
+		if _, ok := activeUsers[host]; !ok {
+			activeUsers[host] = resolveActiveUser(host)
+		}
+		u, err := config.ResolveGitHubUser(prof.GHConfigDir, host, prof.User)
+		if err == nil && u == activeUsers[host] {
+			return i
+		}
+	}
+	return -1
 }
 
 // extractLlsFlags は引数から -a/--all と -j/--json を検出する。-aj等の結合フラグにも対応。
@@ -191,6 +519,30 @@ func extractLlsFlags(args []string) (allFlag, jsonFlag bool) {
 	return
 }
 
+// extractStatusFilter は lls の --dirty と --ahead を読み取る。
+func extractStatusFilter(args []string) domain.StatusFilter {
+	var f domain.StatusFilter
+	for _, a := range args {
+		switch a {
+		case "--dirty":
+			f.Dirty = true
+		case "--ahead":
+			f.Ahead = true
+		}
+	}
+	return f
+}
+
+// extractJSONFlag は引数に --json/-j が含まれるかを返す。
+func extractJSONFlag(args []string) bool {
+	for _, a := range args {
+		if a == "--json" || a == "-j" {
+			return true
+		}
+	}
+	return false
+}
+
 // extractAllFlag は引数から --all/-a を検出・除去し、残りの引数とフラグの有無を返す。
 func extractAllFlag(args []string) ([]string, bool) {
 	var rest []string
@@ -205,24 +557,125 @@ func extractAllFlag(args []string) ([]string, bool) {
 	return rest, all
 }
 
+// newLoader は --lenient の指定を反映した Loader を返す。
+func newLoader(configPath string, lenient bool) *config.Loader {
+	loader := config.NewLoader(configPath)
+	loader.SetLenient(lenient)
+	return loader
+}
+
+// extractLenientFlag は引数から --lenient を検出・除去し、残りの引数とフラグの有無を返す。
+// "--" 以降は実行するコマンドの引数として扱い、抽出しない。
+func extractLenientFlag(args []string) ([]string, bool) {
+	var rest []string
+	lenient := false
+	for i, a := range args {
+		if a == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if a == "--lenient" {
+			lenient = true
+			continue
+		}
+		rest = append(rest, a)
+	}
+	return rest, lenient
+}
+
+// extractTagFilter は引数から --tag と --exclude-tag を抽出し、残りの引数を返す。
+// どちらも繰り返し指定でき、カンマ区切りで複数のタグを渡せる。
+func extractTagFilter(args []string) (domain.TagFilter, []string) {
+	include, args := extractListFlag(args, "--tag")
+	exclude, args := extractListFlag(args, "--exclude-tag")
+	return domain.TagFilter{Include: include, Exclude: exclude}, args
+}
+
+// requireAllForTags はタグの絞り込みが --all と一緒に指定されているかを確かめる。
+func requireAllForTags(filter domain.TagFilter, allFlag bool) error {
+	if !filter.IsZero() && !allFlag {
+		return errors.New("--tag and --exclude-tag require --all")
+	}
+	return nil
+}
+
+// extractListFlag は引数から name <value> と name=<value> をすべて抽出し、カンマで分割した値と残りの引数を返す。
+// "--" 以降は実行するコマンドの引数として扱い、抽出しない。
+func extractListFlag(args []string, name string) ([]string, []string) {
+	var values []string
+	var rest []string
+	add := func(v string) {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+	}
+
+	for i := 0; i < len(args); i++ {
+		if args[i] == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if args[i] == name && i+1 < len(args) {
+			add(args[i+1])
+			i++ // skip value
+			continue
+		}
+		if v, ok := strings.CutPrefix(args[i], name+"="); ok {
+			add(v)
+			continue
+		}
+		rest = append(rest, args[i])
+	}
+	return values, rest
+}
+
 // extractUserFlag は引数から --user <value> を抽出し、残りの引数を返す。
+// "--" 以降は実行するコマンドの引数として扱い、抽出しない。
 func extractUserFlag(args []string) (string, []string) {
-	var user string
+	return extractValueFlag(args, "--user")
+}
+
+// extractConfigFlag は引数から --config <path> を抽出し、残りの引数を返す。
+func extractConfigFlag(args []string) (string, []string) {
+	return extractValueFlag(args, "--config")
+}
+
+// extractValueFlag は引数から name <value> または name=<value> を抽出し、残りの引数を返す。
+// "--" 以降は実行するコマンドの引数として扱い、抽出しない。
+func extractValueFlag(args []string, name string) (string, []string) {
+	var value string
 	var rest []string
 
 	for i := 0; i < len(args); i++ {
-		if args[i] == "--user" && i+1 < len(args) {
-			user = args[i+1]
+		if args[i] == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if args[i] == name && i+1 < len(args) {
+			value = args[i+1]
 			i++ // skip value
 			continue
 		}
+		if v, ok := strings.CutPrefix(args[i], name+"="); ok {
+			value = v
+			continue
+		}
 		rest = append(rest, args[i])
 	}
-	return user, rest
+	return value, rest
 }
 
 var activeAccountRe = regexp.MustCompile(`account (\S+)`)
 
+// writeJSON は v をインデント付き JSON で標準出力に書き出す。
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
 // viewInPager は内容をページャ経由で表示する。
 func viewInPager(content []byte) error {
 	pager := os.Getenv("GH_PAGER")
@@ -239,9 +692,16 @@ func viewInPager(content []byte) error {
 	return cmd.Run()
 }
 
-// resolveActiveUser は gh auth status --active の出力からアクティブユーザー名を返す。
-func resolveActiveUser() string {
-	out, err := exec.Command("gh", "auth", "status", "--active").CombinedOutput()
+// ghActiveUser は resolveActiveUser を app.ActiveUserResolver として使うためのアダプタ。
+type ghActiveUser struct{}
+
+func (ghActiveUser) ResolveActiveUser(host string) string {
+	return resolveActiveUser(host)
+}
+
+// resolveActiveUser は gh auth status --active の出力から host のアクティブユーザー名を返す。
+func resolveActiveUser(host string) string {
+	out, err := exec.Command("gh", "auth", "status", "--active", "--hostname", host).CombinedOutput()
 	if err != nil {
 		return ""
 	}
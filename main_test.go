@@ -1,7 +1,11 @@
 package main
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/sarrrrry/gh-mrepo/internal/config"
+	"github.com/sarrrrry/gh-mrepo/internal/domain"
 )
 
 func TestExtractAllFlag(t *testing.T) {
@@ -104,3 +108,254 @@ func TestExtractLlsFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractStatusFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want domain.StatusFilter
+	}{
+		{name: "no flags", args: []string{"-a"}, want: domain.StatusFilter{}},
+		{name: "--dirty", args: []string{"--dirty"}, want: domain.StatusFilter{Dirty: true}},
+		{name: "--ahead", args: []string{"-j", "--ahead"}, want: domain.StatusFilter{Ahead: true}},
+		{name: "both", args: []string{"--dirty", "--ahead"}, want: domain.StatusFilter{Dirty: true, Ahead: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractStatusFilter(tt.args); got != tt.want {
+				t.Errorf("extractStatusFilter(%v) = %+v, want %+v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractUserFlag(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		wantUser string
+		wantRest []string
+	}{
+		{
+			name:     "no flag",
+			args:     []string{"repo", "list"},
+			wantUser: "",
+			wantRest: []string{"repo", "list"},
+		},
+		{
+			name:     "--user before command",
+			args:     []string{"--user", "work", "repo", "list"},
+			wantUser: "work",
+			wantRest: []string{"repo", "list"},
+		},
+		{
+			name:     "--user after command",
+			args:     []string{"repo", "list", "--user", "work"},
+			wantUser: "work",
+			wantRest: []string{"repo", "list"},
+		},
+		{
+			name:     "--user after -- is kept",
+			args:     []string{"--user", "work", "exec", "--", "tool", "--user", "other"},
+			wantUser: "work",
+			wantRest: []string{"exec", "--", "tool", "--user", "other"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotUser, gotRest := extractUserFlag(tt.args)
+			if gotUser != tt.wantUser {
+				t.Errorf("user = %q, want %q", gotUser, tt.wantUser)
+			}
+			if strings.Join(gotRest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("rest = %v, want %v", gotRest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestExtractJSONFlag(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{args: nil, want: false},
+		{args: []string{"--json"}, want: true},
+		{args: []string{"-j"}, want: true},
+		{args: []string{"--all"}, want: false},
+	}
+	for _, tt := range tests {
+		if got := extractJSONFlag(tt.args); got != tt.want {
+			t.Errorf("extractJSONFlag(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestParseAddArgs(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantFile   string
+		wantValues []config.KeyValue
+		wantErr    bool
+	}{
+		{
+			name:       "key=value のみ",
+			args:       []string{"gh_config_dir=~/.config/gh-work", "root=~/repos"},
+			wantValues: []config.KeyValue{{Key: "gh_config_dir", Value: "~/.config/gh-work"}, {Key: "root", Value: "~/repos"}},
+		},
+		{
+			name:       "--file を指定",
+			args:       []string{"--file", "work.toml", "gh_config_dir=x"},
+			wantFile:   "work.toml",
+			wantValues: []config.KeyValue{{Key: "gh_config_dir", Value: "x"}},
+		},
+		{
+			name:       "値に = を含む",
+			args:       []string{"git_config_name=a=b"},
+			wantValues: []config.KeyValue{{Key: "git_config_name", Value: "a=b"}},
+		},
+		{name: "= が無い", args: []string{"root"}, wantErr: true},
+		{name: "--file の値が無い", args: []string{"--file"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file, values, err := parseAddArgs(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if file != tt.wantFile {
+				t.Errorf("file = %q, want %q", file, tt.wantFile)
+			}
+			if len(values) != len(tt.wantValues) {
+				t.Fatalf("values = %v, want %v", values, tt.wantValues)
+			}
+			for i := range values {
+				if values[i] != tt.wantValues[i] {
+					t.Errorf("values[%d] = %v, want %v", i, values[i], tt.wantValues[i])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractMigrateFlags(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantFrom   string
+		wantDryRun bool
+	}{
+		{name: "フラグなし", args: nil},
+		{name: "--dry-run", args: []string{"--dry-run"}, wantDryRun: true},
+		{name: "-n", args: []string{"-n"}, wantDryRun: true},
+		{name: "--from と --dry-run", args: []string{"--from", "/tmp/gh", "--dry-run"}, wantFrom: "/tmp/gh", wantDryRun: true},
+		{name: "--from の値が無い", args: []string{"--from"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, dryRun := extractMigrateFlags(tt.args)
+			if from != tt.wantFrom || dryRun != tt.wantDryRun {
+				t.Errorf("extractMigrateFlags(%v) = (%q, %v), want (%q, %v)", tt.args, from, dryRun, tt.wantFrom, tt.wantDryRun)
+			}
+		})
+	}
+}
+
+func TestExtractConfigFlag(t *testing.T) {
+	tests := []struct {
+		name       string
+		args       []string
+		wantConfig string
+		wantRest   []string
+	}{
+		{name: "no flag", args: []string{"ls"}, wantRest: []string{"ls"}},
+		{name: "--config <path>", args: []string{"--config", "/tmp/c.toml", "ls"}, wantConfig: "/tmp/c.toml", wantRest: []string{"ls"}},
+		{name: "--config=<path>", args: []string{"ls", "--config=/tmp/c.toml"}, wantConfig: "/tmp/c.toml", wantRest: []string{"ls"}},
+		{
+			name:       "--config after -- is kept",
+			args:       []string{"--config", "a.toml", "exec", "--", "tool", "--config", "b.toml"},
+			wantConfig: "a.toml",
+			wantRest:   []string{"exec", "--", "tool", "--config", "b.toml"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotConfig, gotRest := extractConfigFlag(tt.args)
+			if gotConfig != tt.wantConfig {
+				t.Errorf("config = %q, want %q", gotConfig, tt.wantConfig)
+			}
+			if strings.Join(gotRest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("rest = %v, want %v", gotRest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestExtractLenientFlag(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantRest    []string
+		wantLenient bool
+	}{
+		{name: "no flag", args: []string{"ls"}, wantRest: []string{"ls"}},
+		{name: "--lenient", args: []string{"--lenient", "lls", "--all"}, wantRest: []string{"lls", "--all"}, wantLenient: true},
+		{
+			name:     "--lenient after -- is kept",
+			args:     []string{"exec", "--", "tool", "--lenient"},
+			wantRest: []string{"exec", "--", "tool", "--lenient"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotRest, gotLenient := extractLenientFlag(tt.args)
+			if gotLenient != tt.wantLenient {
+				t.Errorf("lenient = %v, want %v", gotLenient, tt.wantLenient)
+			}
+			if strings.Join(gotRest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("rest = %v, want %v", gotRest, tt.wantRest)
+			}
+		})
+	}
+}
+
+func TestExtractTagFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantInclude []string
+		wantExclude []string
+		wantRest    []string
+	}{
+		{name: "no flag", args: []string{"-a"}, wantRest: []string{"-a"}},
+		{
+			name:        "repeated and comma separated",
+			args:        []string{"--tag", "work", "-a", "--tag=oss,personal", "--exclude-tag", "archived"},
+			wantInclude: []string{"work", "oss", "personal"},
+			wantExclude: []string{"archived"},
+			wantRest:    []string{"-a"},
+		},
+		{
+			name:     "--tag after -- is kept",
+			args:     []string{"-a", "--", "--tag", "work"},
+			wantRest: []string{"-a", "--", "--tag", "work"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, rest := extractTagFilter(tt.args)
+			if strings.Join(filter.Include, ",") != strings.Join(tt.wantInclude, ",") {
+				t.Errorf("Include = %v, want %v", filter.Include, tt.wantInclude)
+			}
+			if strings.Join(filter.Exclude, ",") != strings.Join(tt.wantExclude, ",") {
+				t.Errorf("Exclude = %v, want %v", filter.Exclude, tt.wantExclude)
+			}
+			if strings.Join(rest, " ") != strings.Join(tt.wantRest, " ") {
+				t.Errorf("rest = %v, want %v", rest, tt.wantRest)
+			}
+		})
+	}
+}